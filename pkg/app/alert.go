@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 
+	"github.com/GianlucaP106/mynav/pkg/core"
 	"github.com/GianlucaP106/mynav/pkg/tui"
 	"github.com/awesome-gocui/gocui"
 	"github.com/gookit/color"
@@ -60,3 +61,64 @@ func alert(onConfirm func(bool), title string) *Alert {
 
 	return cd
 }
+
+// Asks to confirm the deletion of an item with git work at risk.
+// The risks are listed above an editor where the name of the item must be typed to confirm.
+func riskAlert(onConfirm func(), name string, risks []*core.GitRisk) *Alert {
+	cd := &Alert{}
+	cd.title = fmt.Sprintf("%s has unsaved git work that will be lost:", name)
+
+	height := len(risks) + 3
+	cd.view = a.ui.SetCenteredView(ConfirmationDialog, 80, height, -(height/2 + 2), 0)
+	cd.view.Wrap = true
+	cd.view.Title = " Warning "
+	a.styleView(cd.view)
+	cd.view.FrameColor = gocui.ColorRed
+	cd.view.TitleColor = gocui.ColorRed
+
+	fmt.Fprintln(cd.view, color.Danger.Sprint(" "+cd.title))
+	fmt.Fprintln(cd.view)
+	for _, risk := range risks {
+		fmt.Fprintln(cd.view, "  - "+risk.String())
+	}
+
+	ed := editor(func(s string) {
+		a.ui.DeleteView(cd.view)
+		if s != name {
+			toast("Name did not match, nothing was deleted", toastWarn)
+			return
+		}
+
+		onConfirm()
+	}, func() {
+		a.ui.DeleteView(cd.view)
+	}, fmt.Sprintf("Type %s to confirm", name), smallEditorSize, "")
+	ed.view.Subtitle = " <Esc> to cancel "
+
+	return cd
+}
+
+// Scans the git work at risk in the background and asks to confirm the deletion.
+// A simple confirmation is asked if nothing is at risk.
+func confirmDelete(onConfirm func(), name string, question string, scan func() ([]*core.GitRisk, error)) {
+	go func() {
+		risks, err := scan()
+		a.ui.Update(func() {
+			if err != nil {
+				toast(err.Error(), toastError)
+				return
+			}
+
+			if len(risks) > 0 {
+				riskAlert(onConfirm, name, risks)
+				return
+			}
+
+			alert(func(b bool) {
+				if b {
+					onConfirm()
+				}
+			}, question)
+		})
+	}()
+}
@@ -29,6 +29,16 @@ type App struct {
 	preview    *Preview
 	info       *Info
 
+	// background jobs (e.g. git clones)
+	jobs *Jobs
+
+	// preview showing the output of tmux, the search dialog preview while it is open
+	activePreview atomic.Pointer[Preview]
+
+	// throttles the refreshes caused by tmux changes
+	outputThrottle *Throttler
+	layoutThrottle *Throttler
+
 	// worker for processing tasks in FIFO and debouncing
 	worker *Worker
 
@@ -38,6 +48,9 @@ type App struct {
 	// if a session is currently attached (or yielding to another process)
 	// background workers can use this to avoid consuming ressources
 	attached atomic.Bool
+
+	// if the app should exit after opening a session (running as a popup)
+	popup bool
 }
 
 // worker magic numbers
@@ -64,6 +77,9 @@ var (
 	timestampColor              = color.New(color.FgDarkGray, color.OpItalic)
 	sessionMarkerColor          = color.New(color.FgGreen, color.Bold)
 	alternateSessionMarkerColor = color.New(color.Magenta, color.Bold)
+	tagsColor                   = color.New(color.FgCyan)
+	descriptionColor            = color.New(color.FgWhite, color.OpItalic)
+	linkedColor                 = color.New(color.FgMagenta, color.OpItalic)
 )
 
 // global a instance
@@ -84,7 +100,9 @@ func newApp() *App {
 // Starts the app.
 func (a *App) start() {
 	// run cli and handle args
-	newCli().run()
+	cli := newCli()
+	cli.run()
+	a.popup = cli.isPopup()
 
 	// init start refresh queue
 	a.worker = newWorker(200*time.Millisecond, defaultWorkerSize)
@@ -101,6 +119,31 @@ func (a *App) start() {
 			log.Panicln(err)
 		}
 	}
+
+	a.saveSessions()
+	a.stopControl()
+	a.stopJobs()
+}
+
+// Stops listening to tmux changes.
+func (a *App) stopControl() {
+	if a.api != nil {
+		a.api.StopControl()
+	}
+}
+
+// Cancels the running jobs so that they roll back what they started, waiting for them at most jobsStopTimeout.
+func (a *App) stopJobs() {
+	if a.jobs != nil {
+		a.jobs.stop(jobsStopTimeout)
+	}
+}
+
+// Saves a snapshot of the workspace sessions so that they can be restored after the tmux server stops.
+func (a *App) saveSessions() {
+	if a.api != nil {
+		a.api.AutoSaveSessions()
+	}
 }
 
 // Inits the app (api, tui, views).
@@ -209,6 +252,7 @@ func (a *App) initUI() {
 	a.sessions = sv
 	a.preview = pv
 	a.info = wiv
+	a.jobs = newJobs()
 
 	// set manager functions that render the views
 	a.ui.SetManager(func(t *tui.TUI) error {
@@ -218,6 +262,7 @@ func (a *App) initUI() {
 		sv.render()
 		wiv.render()
 		pv.render()
+		a.jobs.render()
 		return nil
 	})
 
@@ -228,6 +273,8 @@ func (a *App) initUI() {
 	sv.init()
 	wiv.init()
 	pv.init(a.ui.SetView(getViewPosition(PreviewView)))
+	a.activePreview.Store(pv)
+	a.startControl()
 
 	// set global key bindings
 	a.initGlobalKeys()
@@ -437,17 +484,134 @@ func (a *App) runAction(f func() error) error {
 	err := f()
 	tui.Resume()
 	a.attached.Store(false)
+
+	// changes were not tracked while suspended
+	a.onControlEvent(core.ControlLayout)
 	return err
 }
 
+// Opens a session by running open.
+// Outside of tmux the ui is suspended while the session is attached.
+// Inside tmux the current client is switched to the session while the app keeps running.
+// In popup mode the app exits once the session is opened.
+func (a *App) openSession(name string, open func() error) {
+	if core.IsTmuxSession() {
+		if err := open(); err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		if a.popup {
+			a.exit()
+		}
+
+		toast("Switched to session "+name, toastInfo)
+		return
+	}
+
+	start := time.Now()
+	err := a.runAction(open)
+	if err != nil {
+		toast(err.Error(), toastError)
+		return
+	}
+
+	if a.popup {
+		a.exit()
+	}
+
+	timeTaken := time.Since(start)
+	s := fmt.Sprintf("Detached session %s - %s active", name, core.TimeDeltaStr(timeTaken))
+	toast(s, toastInfo)
+}
+
+// Opens the workspace pinned to the slot.
+func (a *App) openPinned(slot int) {
+	w := a.api.PinnedWorkspace(slot)
+	if w == nil {
+		toast(fmt.Sprintf("Nothing is pinned to slot %d", slot), toastWarn)
+		return
+	}
+
+	a.openSession(w.Name, func() error {
+		return a.api.OpenWorkspace(w)
+	})
+	a.refresh(w.Topic, w, nil)
+}
+
+// Jumps to the previous (offset -1) or next (offset 1) entry of the navigation history.
+func (a *App) jumpHistory(offset int) {
+	index, e := a.api.HistoryTarget(offset)
+	if e == nil {
+		toast("No more history", toastWarn)
+		return
+	}
+
+	a.openSession(e.Name(), func() error {
+		return a.api.JumpHistory(index)
+	})
+	a.refreshHistory(e)
+}
+
+// Switches to the workspace or session visited before the current one.
+func (a *App) togglePrevious() {
+	e := a.api.PreviousHistoryEntry()
+	if e == nil {
+		toast("No previous workspace or session", toastWarn)
+		return
+	}
+
+	a.openSession(e.Name(), func() error {
+		return a.api.OpenHistoryEntry(e)
+	})
+	a.refreshHistory(e)
+}
+
+// Refreshes the views, selecting the workspace of the history entry.
+func (a *App) refreshHistory(e *core.HistoryEntry) {
+	if w := a.api.HistoryWorkspace(e); w != nil {
+		a.refresh(w.Topic, w, nil)
+		return
+	}
+	a.refreshAll()
+}
+
+// Switches the view to the next sort mode.
+func (a *App) cycleSort(view string) {
+	mode := a.api.SortMode(view).Next()
+	a.api.SetSortMode(view, mode)
+	toast("Sorted by "+sortLabel(view), toastInfo)
+}
+
+// Returns the name of the sort mode of the view as shown to the user.
+func sortLabel(view string) string {
+	mode := a.api.SortMode(view)
+	if mode != core.SortRecent {
+		return string(mode)
+	}
+
+	if view == SessionsView {
+		return "last attached"
+	}
+	return "last modified"
+}
+
+// Closes the ui and exits the process.
+func (a *App) exit() {
+	a.saveSessions()
+	a.stopControl()
+	a.stopJobs()
+	a.ui.Close()
+	os.Exit(0)
+}
+
 // Closes the app after count seconds and displays a ticker as a toast.
 func (a *App) closeAfter(count int, delay time.Duration) {
 	time.AfterFunc(delay, func() {
 		ticker := time.NewTicker(time.Second)
 		for range ticker.C {
 			if count == 0 {
-				a.ui.Close()
-				os.Exit(0)
+				a.exit()
 			}
 			a.ui.Update(func() {
 				toast(fmt.Sprintf("Closing in %d seconds...", count), toastWarn)
@@ -474,6 +638,17 @@ func (a *App) initGlobalKeys() {
 		Set('>', "Cycle preview right", func() {
 			a.preview.increment()
 		}).
+		Set('z', "Zoom preview", func() {
+			if !a.initialized.Load() {
+				return
+			}
+
+			a.preview.zoom()
+		}).
+		Set('N', "Toggle notes preview", func() {
+			a.preview.toggleNotes()
+			a.preview.render()
+		}).
 		Set('s', "Search", func() {
 			// block if not initialized to avoid broken state
 			if !a.initialized.Load() {
@@ -481,5 +656,85 @@ func (a *App) initGlobalKeys() {
 			}
 
 			newGlobalSearch().init()
+		}).
+		Set('T', "Open trash", func() {
+			if !a.initialized.Load() {
+				return
+			}
+
+			trash()
+		}).
+		Set(gocui.KeyCtrlZ, "Undo last delete, rename or move", func() {
+			if !a.initialized.Load() {
+				return
+			}
+
+			// ctrl keys reach global bindings from dialogs too
+			if v := a.ui.FocusedView(); v != nil && v.Editable {
+				return
+			}
+
+			a.undo()
 		})
+
+	// ctrl-i is sent as tab by terminals
+	a.ui.KeyBinding(nil).
+		Set(gocui.KeyCtrlO, "Jump back in history", func() {
+			if !a.initialized.Load() {
+				return
+			}
+
+			if a.ignoreNavigation() {
+				return
+			}
+
+			a.jumpHistory(-1)
+		}).
+		Set(gocui.KeyTab, "Jump forward in history", func() {
+			if !a.initialized.Load() {
+				return
+			}
+
+			if a.ignoreNavigation() {
+				return
+			}
+
+			a.jumpHistory(1)
+		}).
+		Set('-', "Switch to previous workspace or session", func() {
+			if !a.initialized.Load() || a.ignoreNavigation() {
+				return
+			}
+
+			a.togglePrevious()
+		})
+
+	for slot := 1; slot <= core.PinSlots; slot++ {
+		a.ui.KeyBinding(nil).Set(rune('0'+slot), fmt.Sprintf("Open pinned workspace %d", slot), func() {
+			if !a.initialized.Load() || a.ignoreNavigation() {
+				return
+			}
+
+			a.openPinned(slot)
+		})
+	}
+}
+
+// Returns if the global keys switching sessions must be ignored.
+// Ctrl keys reach global bindings from dialogs too, and the zoomed preview must not be left by a stray key.
+func (a *App) ignoreNavigation() bool {
+	v := a.ui.FocusedView()
+	return v != nil && (v.Editable || v.Name() == ZoomView)
+}
+
+// Reverts the last delete, rename or move.
+func (a *App) undo() {
+	description, err := a.api.Undo()
+	if err != nil {
+		toast(err.Error(), toastError)
+		return
+	}
+
+	a.refreshAll()
+	toast("Undid "+description, toastInfo)
 }
@@ -16,6 +16,7 @@ type (
 	CliArgs struct {
 		version *bool
 		path    *string
+		popup   *bool
 	}
 )
 
@@ -27,15 +28,26 @@ func (cli *Cli) run() {
 	cli.parseArgs()
 	cli.handleVersionFlag()
 	cli.handlePathFlag()
+	cli.handleCommand()
 }
 
 func (cli *Cli) parseArgs() {
 	version := flag.Bool("version", false, "Version of mynav")
 	path := flag.String("path", ".", "Path to open mynav in")
+	popup := flag.Bool("popup", false, "Exit after opening a session (for use in a tmux popup)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: mynav [flags] [command]")
+		fmt.Fprintln(flag.CommandLine.Output())
+		fmt.Fprintln(flag.CommandLine.Output(), "Flags:")
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		printCommandsUsage(flag.CommandLine.Output())
+	}
 	flag.Parse()
 	cli.args = &CliArgs{
 		version: version,
 		path:    path,
+		popup:   popup,
 	}
 }
 
@@ -53,3 +65,17 @@ func (cli *Cli) handlePathFlag() {
 		}
 	}
 }
+
+// Returns if mynav should exit after opening a session.
+func (cli *Cli) isPopup() bool {
+	return cli.args.popup != nil && *cli.args.popup
+}
+
+func (cli *Cli) handleCommand() {
+	// no positional arguments means we start the tui
+	if flag.NArg() == 0 {
+		return
+	}
+
+	os.Exit(runCommand(flag.Args()))
+}
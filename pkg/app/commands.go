@@ -0,0 +1,1088 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/GianlucaP106/mynav/pkg/core"
+)
+
+// Exit codes used by the headless commands.
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Command is a headless subcommand that drives the api without starting the ui.
+type Command struct {
+	// verb used to invoke the command (e.g. "list")
+	name string
+
+	// positional arguments shown in the usage (e.g. "<topic> <name>")
+	usage string
+
+	// short description shown in the usage
+	description string
+
+	// number of required positional arguments
+	args int
+
+	// registers the optional flags of the command
+	setFlags func(fs *flag.FlagSet)
+
+	// runs the command with the positional arguments
+	run func(api *core.API, args []string) error
+}
+
+// CommandGroup groups commands under a noun (e.g. "mynav topic ...").
+type CommandGroup struct {
+	name     string
+	commands []*Command
+}
+
+// usageError signals that the command was invoked incorrectly.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// Returns the command with the given name, nil if not found.
+func (g *CommandGroup) command(name string) *Command {
+	for _, c := range g.commands {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Prints the usage of this group.
+func (g *CommandGroup) printUsage(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	g.writeUsage(tw)
+	tw.Flush()
+}
+
+// Writes the usage lines of this group to the tab writer.
+func (g *CommandGroup) writeUsage(tw *tabwriter.Writer) {
+	for _, c := range g.commands {
+		fmt.Fprintf(tw, "  mynav %s %s %s\t%s\n", g.name, c.name, c.usage, c.description)
+	}
+}
+
+// Returns the commands invoked without a group (e.g. "mynav open").
+func topLevelCommands() []*Command {
+	return []*Command{
+		openCommand(),
+		importCommand(),
+		exportCommand(),
+		applyCommand(),
+	}
+}
+
+// Returns the top level command with the given name, nil if not found.
+func topLevelCommand(name string) *Command {
+	for _, c := range topLevelCommands() {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Returns all the command groups.
+func commandGroups() []*CommandGroup {
+	return []*CommandGroup{
+		topicCommands(),
+		workspaceCommands(),
+		sessionCommands(),
+		trashCommands(),
+	}
+}
+
+// Returns the command group with the given name, nil if not found.
+func commandGroup(name string) *CommandGroup {
+	for _, g := range commandGroups() {
+		if g.name == name {
+			return g
+		}
+	}
+
+	return nil
+}
+
+// Prints the usage of all commands.
+func printCommandsUsage(w io.Writer) {
+	fmt.Fprintln(w, "Commands:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range topLevelCommands() {
+		fmt.Fprintf(tw, "  mynav %s %s\t%s\n", c.name, c.usage, c.description)
+	}
+	for _, g := range commandGroups() {
+		g.writeUsage(tw)
+	}
+	tw.Flush()
+}
+
+// Runs the command described by args and returns the exit code.
+func runCommand(args []string) int {
+	if cmd := topLevelCommand(args[0]); cmd != nil {
+		return execCommand(cmd.name, cmd, args[1:])
+	}
+
+	group := commandGroup(args[0])
+	if group == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		printCommandsUsage(os.Stderr)
+		return exitUsage
+	}
+
+	if len(args) < 2 {
+		group.printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	cmd := group.command(args[1])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown %s command %q\n\n", group.name, args[1])
+		group.printUsage(os.Stderr)
+		return exitUsage
+	}
+
+	return execCommand(group.name+" "+cmd.name, cmd, args[2:])
+}
+
+// Parses the flags of cmd, invoked as "mynav <path>", and runs it with the remaining arguments.
+// Returns the exit code.
+func execCommand(path string, cmd *Command, args []string) int {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: mynav %s %s\n", path, cmd.usage)
+		fs.PrintDefaults()
+	}
+	if cmd.setFlags != nil {
+		cmd.setFlags(fs)
+	}
+
+	cmdArgs, err := parseCommandFlags(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOk
+	}
+	if err != nil {
+		return exitUsage
+	}
+
+	if len(cmdArgs) < cmd.args {
+		fmt.Fprintf(os.Stderr, "usage: mynav %s %s\n", path, cmd.usage)
+		return exitUsage
+	}
+
+	api, err := core.NewApi("")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return exitError
+	}
+
+	if api == nil {
+		fmt.Fprintln(os.Stderr, "no mynav configuration found in this directory or its parents")
+		return exitError
+	}
+
+	if err := cmd.run(api, cmdArgs); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		var ue *usageError
+		if errors.As(err, &ue) {
+			fmt.Fprintf(os.Stderr, "usage: mynav %s %s\n", path, cmd.usage)
+			return exitUsage
+		}
+		return exitError
+	}
+
+	return exitOk
+}
+
+// Parses the flags allowing them to be interspersed with positional arguments.
+// Returns the positional arguments, all the arguments after "--" are positional.
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		// the flag set stops after "--", the rest must not be parsed again
+		rest := fs.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Returns an error listing the git work at risk, nil if nothing is at risk.
+func riskError(name string, risks []*core.GitRisk) error {
+	if len(risks) == 0 {
+		return nil
+	}
+
+	lines := []string{name + " has unsaved git work that would be lost:"}
+	for _, risk := range risks {
+		lines = append(lines, "  - "+risk.String())
+	}
+	lines = append(lines, "use --force to delete anyway")
+
+	return errors.New(strings.Join(lines, "\n"))
+}
+
+// Prints v as indented json to stdout.
+func printJson(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Looks up a topic by name, returns an error if it does not exist.
+func lookupTopic(api *core.API, name string) (*core.Topic, error) {
+	t := api.Topic(name)
+	if t == nil {
+		return nil, fmt.Errorf("topic %s does not exist", name)
+	}
+
+	return t, nil
+}
+
+// Looks up a workspace by its short path (topic/workspace), returns an error if it does not exist.
+func lookupWorkspace(api *core.API, shortPath string) (*core.Workspace, error) {
+	w := api.Workspace(shortPath)
+	if w == nil {
+		return nil, fmt.Errorf("workspace %s does not exist", shortPath)
+	}
+
+	return w, nil
+}
+
+// Looks up a session by its name or by the short path of its workspace.
+func lookupSession(api *core.API, name string) (*core.Session, error) {
+	if w := api.Workspace(name); w != nil {
+		if s := api.Session(w); s != nil {
+			return s, nil
+		}
+	}
+
+	for _, s := range api.AllSessions() {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+
+	return nil, fmt.Errorf("session %s does not exist", name)
+}
+
+// Looks up an item of the trash by its id, returns an error if it is not in the trash.
+func lookupTrashItem(api *core.API, id string) (*core.TrashItem, error) {
+	item := api.TrashItem(id)
+	if item == nil {
+		return nil, fmt.Errorf("item %s is not in the trash", id)
+	}
+
+	return item, nil
+}
+
+func topicCommands() *CommandGroup {
+	return &CommandGroup{
+		name: "topic",
+		commands: []*Command{
+			topicListCommand(),
+			{
+				name:        "new",
+				usage:       "<name>",
+				description: "Create a topic",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					_, err := api.NewTopic(args[0])
+					return err
+				},
+			},
+			{
+				name:        "rename",
+				usage:       "<topic> <new-name>",
+				description: "Rename a topic",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					t, err := lookupTopic(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.RenameTopic(t, args[1])
+				},
+			},
+			topicDeleteCommand(),
+		},
+	}
+}
+
+func workspaceCommands() *CommandGroup {
+	return &CommandGroup{
+		name: "workspace",
+		commands: []*Command{
+			workspaceListCommand(),
+			workspaceNewCommand(),
+			{
+				name:        "templates",
+				usage:       "<topic>",
+				description: "List the templates available to the workspaces of a topic",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					t, err := lookupTopic(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					for _, tmpl := range api.Templates(t) {
+						fmt.Fprintf(tw, "%s\t%s\t%s\n", tmpl.String(), core.HomeShortPath(tmpl.Path), tmpl.Description)
+					}
+					return tw.Flush()
+				},
+			},
+			{
+				name:        "rename",
+				usage:       "<topic/workspace> <new-name>",
+				description: "Rename a workspace",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.RenameWorkspace(w, args[1])
+				},
+			},
+			{
+				name:        "move",
+				usage:       "<topic/workspace> <topic>",
+				description: "Move a workspace to another topic",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					t, err := lookupTopic(api, args[1])
+					if err != nil {
+						return err
+					}
+
+					return api.MoveWorkspace(w, t)
+				},
+			},
+			workspaceLinkCommand(),
+			workspaceDeleteCommand(),
+			{
+				name:        "describe",
+				usage:       "<topic/workspace> [description]",
+				description: "Set the description of a workspace, or edit it with $EDITOR if none is given",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					if len(args) == 1 {
+						return api.EditWorkspaceDescription(w)
+					}
+
+					return api.SetWorkspaceDescription(w, strings.Join(args[1:], " "))
+				},
+			},
+			workspaceNotesCommand(),
+			{
+				name:        "tag",
+				usage:       "<topic/workspace> <tag>...",
+				description: "Add tags to a workspace",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.AddWorkspaceTags(w, core.ParseTags(strings.Join(args[1:], " "))...)
+				},
+			},
+			{
+				name:        "untag",
+				usage:       "<topic/workspace> <tag>...",
+				description: "Remove tags from a workspace",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.RemoveWorkspaceTags(w, core.ParseTags(strings.Join(args[1:], " "))...)
+				},
+			},
+			{
+				name:        "open",
+				usage:       "<topic/workspace>",
+				description: "Create and/or attach to (or switch to) the workspace session",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.OpenWorkspace(w)
+				},
+			},
+			{
+				name:        "pin",
+				usage:       "<topic/workspace> <slot>",
+				description: "Pin a workspace to a slot from 1 to 9",
+				args:        2,
+				run: func(api *core.API, args []string) error {
+					w, err := lookupWorkspace(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					slot, err := core.ParsePinSlot(args[1])
+					if err != nil {
+						return err
+					}
+
+					return api.Pin(w, slot)
+				},
+			},
+			{
+				name:        "unpin",
+				usage:       "<slot>",
+				description: "Empty a pin slot",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					slot, err := core.ParsePinSlot(args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.Unpin(slot)
+				},
+			},
+			{
+				name:        "pins",
+				description: "List the pinned workspaces by slot",
+				run: func(api *core.API, args []string) error {
+					tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					for i, w := range api.PinnedWorkspaces() {
+						if w != nil {
+							fmt.Fprintf(tw, "%d\t%s\n", i+1, w.ShortPath())
+						}
+					}
+					return tw.Flush()
+				},
+			},
+		},
+	}
+}
+
+func sessionCommands() *CommandGroup {
+	return &CommandGroup{
+		name: "session",
+		commands: []*Command{
+			sessionListCommand(),
+			{
+				name:        "new",
+				usage:       "<name>",
+				description: "Create a session without attaching",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					if strings.TrimSpace(args[0]) == "" {
+						return &usageError{msg: "name must not be empty"}
+					}
+
+					_, err := api.NewSession(args[0])
+					return err
+				},
+			},
+			{
+				name:        "open",
+				usage:       "<session|topic/workspace>",
+				description: "Attach to (or switch to) a session",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					s, err := lookupSession(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.AttachSession(s)
+				},
+			},
+			{
+				name:        "save",
+				description: "Save a snapshot of the workspace sessions",
+				run: func(api *core.API, args []string) error {
+					count, err := api.SaveSessions()
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("Saved %d sessions\n", count)
+					return nil
+				},
+			},
+			{
+				name:        "restore",
+				description: "Restore the saved workspace sessions that are not running",
+				run: func(api *core.API, args []string) error {
+					count, err := api.RestoreSessions()
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("Restored %d sessions\n", count)
+					return nil
+				},
+			},
+			{
+				name:        "kill",
+				usage:       "<session|topic/workspace>",
+				description: "Kill a session",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					s, err := lookupSession(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.KillSession(s)
+				},
+			},
+		},
+	}
+}
+
+func trashCommands() *CommandGroup {
+	return &CommandGroup{
+		name: "trash",
+		commands: []*Command{
+			{
+				name:        "list",
+				description: "List deleted topics and workspaces",
+				run: func(api *core.API, args []string) error {
+					tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+					for _, item := range api.TrashItems() {
+						fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", item.Id, item.Kind, item.Origin, core.TimeAgo(item.DeletedAt))
+					}
+					return tw.Flush()
+				},
+			},
+			{
+				name:        "restore",
+				usage:       "<id>",
+				description: "Restore a deleted topic or workspace",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					item, err := lookupTrashItem(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.RestoreTrashItem(item)
+				},
+			},
+			{
+				name:        "purge",
+				usage:       "<id>",
+				description: "Permanently delete an item of the trash",
+				args:        1,
+				run: func(api *core.API, args []string) error {
+					item, err := lookupTrashItem(api, args[0])
+					if err != nil {
+						return err
+					}
+
+					return api.PurgeTrashItem(item)
+				},
+			},
+			{
+				name:        "empty",
+				description: "Permanently delete all the items of the trash",
+				run: func(api *core.API, args []string) error {
+					_, err := api.EmptyTrash()
+					return err
+				},
+			},
+		},
+	}
+}
+
+func topicListCommand() *Command {
+	var asJson bool
+	var sortBy string
+	return &Command{
+		name:        "list",
+		usage:       "[--json] [--sort <mode>]",
+		description: "List topics",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&asJson, "json", false, "Print as json")
+			fs.StringVar(&sortBy, "sort", string(core.SortFrecency), "Order by frecency, recent or name")
+		},
+		run: func(api *core.API, args []string) error {
+			mode, err := core.ParseSortMode(sortBy)
+			if err != nil {
+				return err
+			}
+			topics := api.SortTopics(api.Topics(), mode)
+			if asJson {
+				return printJson(api.TopicsSchema(topics))
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for _, t := range topics {
+				fmt.Fprintf(tw, "%s\t%d\t%s\n", t.Name, len(api.Workspaces(t)), core.TimeAgo(t.LastModified()))
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func topicDeleteCommand() *Command {
+	var force bool
+	return &Command{
+		name:        "delete",
+		usage:       "<topic> [--force]",
+		description: "Delete a topic and all its workspaces",
+		args:        1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&force, "force", false, "Delete even if git work would be lost")
+		},
+		run: func(api *core.API, args []string) error {
+			t, err := lookupTopic(api, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				risks, err := api.TopicRisks(t)
+				if err != nil {
+					return err
+				}
+
+				if err := riskError(t.Name, risks); err != nil {
+					return err
+				}
+			}
+
+			return api.DeleteTopic(t)
+		},
+	}
+}
+
+func workspaceNewCommand() *Command {
+	var templateName string
+	return &Command{
+		name:        "new",
+		usage:       "<topic> <name> [--template <name>]",
+		description: "Create a workspace, optionally from a template",
+		args:        2,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&templateName, "template", "", "Template rendered into the workspace")
+		},
+		run: func(api *core.API, args []string) error {
+			t, err := lookupTopic(api, args[0])
+			if err != nil {
+				return err
+			}
+
+			if templateName == "" {
+				_, err = api.NewWorkspace(t, args[1])
+				return err
+			}
+
+			tmpl, err := api.Template(t, templateName)
+			if err != nil {
+				return err
+			}
+
+			_, err = api.NewWorkspaceFromTemplate(t, args[1], tmpl)
+			return err
+		},
+	}
+}
+
+func workspaceListCommand() *Command {
+	var asJson bool
+	var sortBy string
+	return &Command{
+		name:        "list",
+		usage:       "[topic] [tag:<tag>...] [topic:<topic>] [--json] [--sort <mode>]",
+		description: "List workspaces, optionally of a single topic or with tags",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&asJson, "json", false, "Print as json")
+			fs.StringVar(&sortBy, "sort", string(core.SortFrecency), "Order by frecency, recent or name")
+		},
+		run: func(api *core.API, args []string) error {
+			mode, err := core.ParseSortMode(sortBy)
+			if err != nil {
+				return err
+			}
+
+			// a bare argument is the name of a topic, like topic:<topic>
+			query := core.ParseWorkspaceQuery(strings.Join(args, " "))
+			if query.Text != "" {
+				if query.Topic != "" || strings.Contains(query.Text, " ") {
+					return &usageError{msg: "only one topic can be given"}
+				}
+				query.Topic = query.Text
+			}
+
+			// the workspaces of the sub-topics are listed with the ones of the topic
+			workspaces := api.AllWorkspaces()
+			if query.Topic != "" {
+				if _, err := lookupTopic(api, query.Topic); err != nil {
+					return err
+				}
+			}
+
+			filtered := make(core.Workspaces, 0)
+			for _, w := range workspaces {
+				if query.Matches(w, api.WorkspaceTags(w)) {
+					filtered = append(filtered, w)
+				}
+			}
+			workspaces = api.SortWorkspaces(filtered, mode)
+
+			if asJson {
+				return printJson(api.WorkspacesSchema(workspaces))
+			}
+
+			sMap := api.SessionMap()
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for _, w := range workspaces {
+				session := ""
+				if sMap.Get(w) != nil {
+					session = "session"
+				}
+				linked := ""
+				if w.Linked() {
+					linked = "linked"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", w.ShortPath(), session, strings.Join(api.WorkspaceTags(w), ","), core.TimeAgo(w.LastModified()), linked)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func workspaceLinkCommand() *Command {
+	var name string
+	return &Command{
+		name:        "link",
+		usage:       "<topic> <directory> [--name <name>]",
+		description: "Add an external directory to a topic as a linked workspace, without moving it",
+		args:        2,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&name, "name", "", "Name of the workspace, the name of the directory if empty")
+		},
+		run: func(api *core.API, args []string) error {
+			t, err := lookupTopic(api, args[0])
+			if err != nil {
+				return err
+			}
+
+			_, err = api.LinkWorkspace(t, args[1], name)
+			return err
+		},
+	}
+}
+
+func workspaceNotesCommand() *Command {
+	var print bool
+	return &Command{
+		name:        "notes",
+		usage:       "<topic/workspace> [--print]",
+		description: "Edit the notes of a workspace with $EDITOR",
+		args:        1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&print, "print", false, "Print the notes instead of editing them")
+		},
+		run: func(api *core.API, args []string) error {
+			w, err := lookupWorkspace(api, args[0])
+			if err != nil {
+				return err
+			}
+
+			if print {
+				fmt.Print(w.Notes())
+				return nil
+			}
+
+			return api.EditWorkspaceNotes(w)
+		},
+	}
+}
+
+func workspaceDeleteCommand() *Command {
+	var force bool
+	return &Command{
+		name:        "delete",
+		usage:       "<topic/workspace> [--force]",
+		description: "Delete a workspace",
+		args:        1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&force, "force", false, "Delete even if git work would be lost")
+		},
+		run: func(api *core.API, args []string) error {
+			w, err := lookupWorkspace(api, args[0])
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				risks, err := api.WorkspaceRisks(w)
+				if err != nil {
+					return err
+				}
+
+				if err := riskError(w.ShortPath(), risks); err != nil {
+					return err
+				}
+			}
+
+			return api.DeleteWorkspace(w)
+		},
+	}
+}
+
+func sessionListCommand() *Command {
+	var asJson bool
+	var sortBy string
+	return &Command{
+		name:        "list",
+		usage:       "[--json] [--sort <mode>]",
+		description: "List sessions",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&asJson, "json", false, "Print as json")
+			fs.StringVar(&sortBy, "sort", string(core.SortFrecency), "Order by frecency, recent or name")
+		},
+		run: func(api *core.API, args []string) error {
+			mode, err := core.ParseSortMode(sortBy)
+			if err != nil {
+				return err
+			}
+			sessions := api.SortSessions(api.AllSessions(), mode)
+
+			if asJson {
+				return printJson(api.SessionsSchema(sessions))
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for _, s := range sessions {
+				workspace := ""
+				if s.Workspace != nil {
+					workspace = s.Workspace.ShortPath()
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, strconv.Itoa(s.Windows), workspace, core.TimeAgo(core.UnixTime(s.LastAttached)))
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func openCommand() *Command {
+	var slotFlag string
+	return &Command{
+		name:        "open",
+		usage:       "[topic/workspace] [--slot <n>]",
+		description: "Open a workspace, or the workspace pinned to a slot",
+		setFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&slotFlag, "slot", "", "Slot of the pinned workspace to open")
+		},
+		run: func(api *core.API, args []string) error {
+			if slotFlag == "" {
+				if len(args) == 0 {
+					return &usageError{msg: "a workspace or a slot is required"}
+				}
+
+				w, err := lookupWorkspace(api, args[0])
+				if err != nil {
+					return err
+				}
+
+				return api.OpenWorkspace(w)
+			}
+
+			if len(args) > 0 {
+				return &usageError{msg: "a workspace and a slot cannot both be given"}
+			}
+
+			slot, err := core.ParsePinSlot(slotFlag)
+			if err != nil {
+				return err
+			}
+
+			w := api.PinnedWorkspace(slot)
+			if w == nil {
+				return fmt.Errorf("nothing is pinned to slot %d", slot)
+			}
+
+			return api.OpenWorkspace(w)
+		},
+	}
+}
+
+func importCommand() *Command {
+	var groupBy string
+	var modeFlag string
+	var dryRun bool
+	return &Command{
+		name:        "import",
+		usage:       "<directory> [--group owner|parent] [--mode move|link] [--dry-run]",
+		description: "Import the projects found in a directory as workspaces",
+		args:        1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&groupBy, "group", core.GroupByOwner, "Propose topics by git remote owner or by parent directory")
+			fs.StringVar(&modeFlag, "mode", string(core.ImportMove), "Move the projects into the topics, or link them")
+			fs.BoolVar(&dryRun, "dry-run", false, "Print what would be imported without importing")
+		},
+		run: func(api *core.API, args []string) error {
+			mode, err := core.ParseImportMode(modeFlag)
+			if err != nil {
+				return err
+			}
+
+			items, err := api.ScanImport(args[0], groupBy)
+			if err != nil {
+				return err
+			}
+
+			if len(items) == 0 {
+				return fmt.Errorf("no projects found in %s", args[0])
+			}
+
+			failed := 0
+			for _, item := range items {
+				if dryRun || item.Conflict != "" {
+					fmt.Println(item.String(mode))
+					continue
+				}
+
+				if _, err := api.Import(item, mode); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to import %s: %s\n", core.HomeShortPath(item.Source), err)
+					failed++
+					continue
+				}
+				fmt.Println(item.String(mode))
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d projects could not be imported", failed)
+			}
+			return nil
+		},
+	}
+}
+
+func exportCommand() *Command {
+	return &Command{
+		name:        "export",
+		usage:       "[file]",
+		description: "Write a manifest of the topics and workspaces, to stdout if no file is given",
+		run: func(api *core.API, args []string) error {
+			m := api.ExportManifest()
+			if len(args) == 0 {
+				return printJson(m)
+			}
+
+			data, err := json.MarshalIndent(m, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			return os.WriteFile(args[0], append(data, '\n'), 0644)
+		},
+	}
+}
+
+func applyCommand() *Command {
+	var dryRun bool
+	var jobs int
+	return &Command{
+		name:        "apply",
+		usage:       "<manifest> [--dry-run] [--jobs <n>]",
+		description: "Create the topics and workspaces of a manifest that are missing, reporting the drift",
+		args:        1,
+		setFlags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&dryRun, "dry-run", false, "Print the drift without creating anything")
+			fs.IntVar(&jobs, "jobs", core.DefaultManifestJobs, "Number of repos cloned at the same time")
+		},
+		run: func(api *core.API, args []string) error {
+			m, err := core.ReadManifest(args[0])
+			if err != nil {
+				return err
+			}
+
+			drift := api.ManifestDrift(m)
+			for _, d := range drift {
+				fmt.Println(d.String())
+			}
+
+			if dryRun {
+				return nil
+			}
+
+			// interrupting cancels the clones in progress, their workspaces are removed
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			failed := 0
+			results := api.ApplyManifest(ctx, m, jobs, func(r *core.ManifestResult) {
+				if r.Err != nil {
+					fmt.Fprintln(os.Stderr, r.String())
+					return
+				}
+				fmt.Println(r.String())
+			})
+			for _, r := range results {
+				if r.Err != nil {
+					failed++
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d topics and workspaces could not be created", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"time"
+
+	"github.com/GianlucaP106/mynav/pkg/core"
+)
+
+// control magic numbers
+const (
+	// min delay between refreshes of the preview caused by pane output
+	outputRefreshDelay = 100 * time.Millisecond
+
+	// min delay between refreshes of the sessions caused by session and window changes
+	layoutRefreshDelay = 250 * time.Millisecond
+
+	// delay between refreshes of the sessions while tmux does not report changes
+	pollRefreshDelay = 3 * time.Second
+)
+
+// Starts listening to tmux changes to refresh the preview and the sessions view when something changes.
+func (a *App) startControl() {
+	a.outputThrottle = newThrottler(outputRefreshDelay)
+	a.layoutThrottle = newThrottler(layoutRefreshDelay)
+	a.api.StartControl(a.onControlEvent)
+	go a.pollControl()
+}
+
+// Refreshes periodically while no session is watched, as tmux only reports changes (e.g. sessions created
+// outside of mynav) to a control client attached to a session.
+func (a *App) pollControl() {
+	t := time.NewTicker(pollRefreshDelay)
+	defer t.Stop()
+	for range t.C {
+		if !a.api.ControlRunning() {
+			a.onControlEvent(core.ControlLayout)
+		}
+	}
+}
+
+// Handles a change reported by tmux.
+func (a *App) onControlEvent(e core.ControlEvent) {
+	// the ui is suspended, it is refreshed once resumed
+	if a.attached.Load() {
+		return
+	}
+
+	switch e {
+	case core.ControlOutput:
+		a.outputThrottle.Run(func() {
+			p := a.activePreview.Load()
+			p.refresh()
+			a.ui.Update(func() {
+				p.render()
+			})
+		})
+	case core.ControlLayout:
+		a.layoutThrottle.Run(func() {
+			p := a.activePreview.Load()
+			a.sessions.refresh()
+			p.refresh()
+			a.ui.Update(func() {
+				a.sessions.render()
+				p.render()
+			})
+		})
+	}
+}
+
+// Sets the preview that shows the output of tmux (e.g. the preview of the search dialog while it is open).
+func (a *App) setActivePreview(p *Preview) {
+	a.activePreview.Store(p)
+	go p.watch()
+}
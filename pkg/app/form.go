@@ -0,0 +1,205 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GianlucaP106/mynav/pkg/tui"
+	"github.com/awesome-gocui/gocui"
+)
+
+// Form is a dialog with multiple fields.
+// Tab and Shift+Tab cycle through the fields, Enter submits and Esc cancels.
+type Form struct {
+	fields []*FormField
+
+	// index of the focused field
+	focused int
+
+	// view focused before the form was opened
+	prevView *tui.View
+}
+
+// FormField is either a text field or a choice field (if it has options).
+type FormField struct {
+	view *tui.View
+
+	label string
+
+	// value of a text field
+	value string
+
+	// options of a choice field and the selected one
+	options  []string
+	selected int
+}
+
+// form magic numbers
+const (
+	// width of the fields
+	formWidth = 80
+
+	// height of a field (including the frame)
+	formFieldHeight = 3
+)
+
+// Returns a text field with a default value.
+func textField(label string, value string) *FormField {
+	return &FormField{label: label, value: value}
+}
+
+// Returns a choice field, the first option is selected by default.
+func choiceField(label string, options ...string) *FormField {
+	return &FormField{label: label, options: options}
+}
+
+// Opens a form with the fields, onSubmit receives the values of the fields in order.
+func form(onSubmit func(values []string), onCancel func(), title string, fields ...*FormField) *Form {
+	f := &Form{
+		fields:   fields,
+		prevView: a.ui.FocusedView(),
+	}
+
+	submit := func() {
+		values := make([]string, 0)
+		for _, field := range f.fields {
+			values = append(values, field.get())
+		}
+
+		f.close()
+		onSubmit(values)
+	}
+	cancel := func() {
+		f.close()
+		onCancel()
+	}
+
+	for i, field := range f.fields {
+		offset := (i - len(f.fields)/2) * formFieldHeight
+		field.view = a.ui.SetCenteredView(fmt.Sprintf("%s%d", FormDialog, i), formWidth, formFieldHeight-1, offset, 0)
+		field.view.Title = fmt.Sprintf(" %s ", field.label)
+		field.view.Editable = true
+		a.styleView(field.view)
+
+		if field.isChoice() {
+			field.view.Editor = choiceEditor(field, submit, cancel)
+			field.render()
+		} else {
+			field.view.Editor = tui.NewSimpleEditor(func(_ string) {
+				submit()
+			}, cancel, nil)
+			if field.value != "" {
+				fmt.Fprint(field.view, field.value)
+				field.view.MoveCursor(len(field.value), 0)
+			}
+		}
+
+		a.ui.KeyBinding(field.view).
+			Set(gocui.KeyTab, "Next field", func() {
+				f.focus(f.focused + 1)
+			}).
+			Set(gocui.KeyBacktab, "Previous field", func() {
+				f.focus(f.focused - 1)
+			})
+	}
+
+	first := f.fields[0].view
+	first.Title = fmt.Sprintf(" %s - %s ", title, f.fields[0].label)
+	last := f.fields[len(f.fields)-1].view
+	last.Subtitle = " <Tab> next field, <Enter> submit "
+
+	a.ui.Cursor = true
+	f.focus(0)
+
+	return f
+}
+
+// Focuses the field at idx, wrapping around.
+func (f *Form) focus(idx int) {
+	idx = (idx + len(f.fields)) % len(f.fields)
+	f.focused = idx
+	for i, field := range f.fields {
+		if i == idx {
+			field.view.FrameColor = onFrameColor
+			field.view.TitleColor = onTitleColor
+		} else {
+			field.view.FrameColor = offFrameColor
+			field.view.TitleColor = offTitleColor
+		}
+	}
+
+	a.ui.FocusView(f.fields[idx].view)
+}
+
+func (f *Form) close() {
+	a.ui.Cursor = false
+	for _, field := range f.fields {
+		a.ui.DeleteView(field.view)
+	}
+
+	if f.prevView != nil {
+		a.ui.FocusView(f.prevView)
+	}
+}
+
+func (field *FormField) isChoice() bool {
+	return len(field.options) > 0
+}
+
+// Returns the value of the field.
+func (field *FormField) get() string {
+	if field.isChoice() {
+		return field.options[field.selected]
+	}
+
+	return strings.TrimSpace(field.view.Buffer())
+}
+
+// Renders the options of a choice field, highlighting the selected one.
+func (field *FormField) render() {
+	field.view.Clear()
+	line := ""
+	for i, option := range field.options {
+		if i == field.selected {
+			line += sessionMarkerColor.Sprint("[" + option + "]")
+		} else {
+			line += timestampColor.Sprint(" " + option + " ")
+		}
+		line += " "
+	}
+	fmt.Fprint(field.view, line)
+	field.view.SetCursor(0, 0)
+}
+
+// Returns an editor for a choice field that cycles the options with the arrows or space.
+func choiceEditor(field *FormField, onEnter func(), onEsc func()) gocui.EditorFunc {
+	return gocui.EditorFunc(func(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+		switch {
+		case key == gocui.KeyArrowRight || key == gocui.KeySpace || ch == 'l':
+			field.selected = (field.selected + 1) % len(field.options)
+			field.render()
+		case key == gocui.KeyArrowLeft || ch == 'h':
+			field.selected = (field.selected - 1 + len(field.options)) % len(field.options)
+			field.render()
+		case key == gocui.KeyEnter:
+			onEnter()
+		case key == gocui.KeyEsc:
+			onEsc()
+		}
+	})
+}
+
+// Parses a non negative integer field, empty is 0.
+func parseCount(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s is not a valid number", s)
+	}
+
+	return n, nil
+}
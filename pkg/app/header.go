@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"sync/atomic"
 
 	"github.com/GianlucaP106/mynav/pkg/tui"
@@ -15,16 +16,23 @@ type Header struct {
 	wcv *tui.View
 	tcv *tui.View
 
+	// pinned workspaces view
+	pv *tui.View
+
 	// stats data (atomic as they may be modfied by worker)
 	lastWorkspace  atomic.Value
 	sessionCount   atomic.Int32
 	workspaceCount atomic.Int32
 	topicCount     atomic.Int32
+
+	// short paths of the pinned workspaces by slot, empty for the empty slots
+	pinned atomic.Value
 }
 
 func newHeader() *Header {
 	h := &Header{}
 	h.lastWorkspace.Store("")
+	h.pinned.Store([]string{})
 	return h
 }
 
@@ -39,6 +47,16 @@ func (hv *Header) refresh() {
 		hv.lastWorkspace.Store("")
 	}
 
+	pinned := make([]string, 0)
+	for _, w := range a.api.PinnedWorkspaces() {
+		if w != nil {
+			pinned = append(pinned, w.ShortPath())
+		} else {
+			pinned = append(pinned, "")
+		}
+	}
+	hv.pinned.Store(pinned)
+
 	// session count last because it is slow
 	hv.sessionCount.Store(int32(a.api.SessionCount()))
 }
@@ -63,6 +81,12 @@ func (hv *Header) init() {
 	hv.scv = a.ui.SetView(getViewPosition(Header4View))
 	hv.scv.Title = " Sessions "
 	a.styleView(hv.scv)
+
+	// pinned workspaces header config
+	hv.pv = a.ui.SetView(getViewPosition(PinnedView))
+	hv.pv.Title = " Pinned "
+	hv.pv.Subtitle = " <1-9> to open "
+	a.styleView(hv.pv)
 }
 
 func (hv *Header) render() {
@@ -70,6 +94,7 @@ func (hv *Header) render() {
 	hv.renderTopicCount()
 	hv.renderWorkspaceCount()
 	hv.renderSessionCount()
+	hv.renderPinned()
 }
 
 func (hv *Header) renderLastWorkspace() {
@@ -117,3 +142,25 @@ func (hv *Header) renderSessionCount() {
 	count = s.Sprint(count)
 	fmt.Fprintln(hv.scv, count)
 }
+
+func (hv *Header) renderPinned() {
+	// pinned workspaces header section
+	hv.pv.Clear()
+	a.ui.Resize(hv.pv, getViewPosition(hv.pv.Name()))
+
+	slots := make([]string, 0)
+	for i, shortPath := range hv.pinned.Load().([]string) {
+		if shortPath == "" {
+			continue
+		}
+
+		slot := sessionMarkerColor.Sprint(strconv.Itoa(i + 1))
+		slots = append(slots, slot+" "+workspaceNameColor.Sprint(shortPath))
+	}
+
+	if len(slots) == 0 {
+		fmt.Fprintln(hv.pv, timestampColor.Sprint("Press p on a workspace to pin it"))
+		return
+	}
+	fmt.Fprintln(hv.pv, strings.Join(slots, "  "))
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/GianlucaP106/mynav/pkg/core"
+	"github.com/GianlucaP106/mynav/pkg/tui"
+	"github.com/awesome-gocui/gocui"
+	"github.com/gookit/color"
+)
+
+// Import is a dialog previewing the projects found in a directory before importing them as workspaces.
+type Import struct {
+	view *tui.View
+
+	// table renderer
+	table *tui.TableRenderer[*core.ImportItem]
+
+	// projects found in the directory
+	items []*core.ImportItem
+
+	// how the projects are imported
+	mode core.ImportMode
+
+	// items excluded by the user
+	skipped map[*core.ImportItem]bool
+
+	// view focused before the dialog was opened
+	prevView *tui.View
+}
+
+// Styles of the rows of the items that are not imported.
+var skippedImportStyles = []color.Style{
+	timestampColor,
+	timestampColor,
+	timestampColor,
+}
+
+// Asks for the directory to import and opens the preview of the import.
+func importWizard() {
+	form(func(values []string) {
+		dir, groupBy := values[0], values[1]
+		mode, err := core.ParseImportMode(values[2])
+		if err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		// scanning and importing run in the background as large trees take a while
+		toast("Scanning "+dir, toastInfo)
+		go func() {
+			items, err := a.api.ScanImport(dir, groupBy)
+			a.ui.Update(func() {
+				if err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				if len(items) == 0 {
+					toast("No projects found in "+dir, toastWarn)
+					return
+				}
+
+				importPreview(items, mode)
+			})
+		}()
+	}, func() {}, "Import a directory",
+		textField("Directory", ""),
+		choiceField("Topics by", core.GroupByOwner, core.GroupByParent),
+		choiceField("Mode", string(core.ImportMove), string(core.ImportLink)),
+	)
+}
+
+func importPreview(items []*core.ImportItem, mode core.ImportMode) *Import {
+	im := &Import{
+		items:   items,
+		mode:    mode,
+		skipped: make(map[*core.ImportItem]bool),
+	}
+	im.prevView = a.ui.FocusedView()
+	im.view = a.ui.SetCenteredView(ImportDialog, 120, 20, 0, 0)
+	a.styleView(im.view)
+	im.view.TitleColor = onTitleColor
+	im.view.FrameColor = onFrameColor
+	im.view.Title = fmt.Sprintf(" Import (%s) ", mode)
+	im.view.Subtitle = " <Space> include/exclude, <Enter> import "
+
+	x, y := im.view.Size()
+	im.table = tui.NewTableRenderer[*core.ImportItem]()
+	im.table.Init(x, y, []string{"Project", "Workspace", "Status"}, []float64{0.4, 0.3, 0.3})
+	im.table.SetStyles([]color.Style{
+		workspaceNameColor,
+		topicNameColor,
+		sessionMarkerColor,
+	})
+
+	down := func() {
+		im.table.Down()
+		im.show()
+	}
+	up := func() {
+		im.table.Up()
+		im.show()
+	}
+	a.ui.KeyBinding(im.view).
+		Set('j', "Move down", down).
+		Set('k', "Move up", up).
+		Set(gocui.KeyArrowDown, "Move down", down).
+		Set(gocui.KeyArrowUp, "Move up", up).
+		Set(gocui.KeySpace, "Include/exclude project", im.toggle).
+		Set(gocui.KeyEnter, "Import projects", im.apply).
+		Set(gocui.KeyEsc, "Cancel import", im.close).
+		Set('?', "Toggle cheatsheet", func() {
+			help(im.view)
+		})
+
+	im.refresh()
+	a.ui.FocusView(im.view)
+	return im
+}
+
+// Fills the table with the items, keeping the selected row.
+func (im *Import) refresh() {
+	selected, _ := im.table.SelectedRow()
+	rows := make([]*tui.TableRow[*core.ImportItem], 0)
+	for _, item := range im.items {
+		status := string(im.mode)
+		var styles []color.Style
+		switch {
+		case item.Conflict != "":
+			status = item.Conflict
+			styles = skippedImportStyles
+		case im.skipped[item]:
+			status = "excluded"
+			styles = skippedImportStyles
+		}
+
+		rows = append(rows, &tui.TableRow[*core.ImportItem]{
+			Cols:   []string{core.HomeShortPath(item.Source), item.ShortPath(), status},
+			Value:  item,
+			Styles: styles,
+		})
+	}
+	im.table.Fill(rows)
+	im.table.SelectRow(max(selected, 0))
+	im.show()
+}
+
+func (im *Import) toggle() {
+	_, row := im.table.SelectedRow()
+	if row == nil || row.Value.Conflict != "" {
+		return
+	}
+
+	im.skipped[row.Value] = !im.skipped[row.Value]
+	im.refresh()
+}
+
+// Imports the items that are not excluded and closes the dialog.
+func (im *Import) apply() {
+	items := make([]*core.ImportItem, 0)
+	for _, item := range im.items {
+		if item.Conflict == "" && !im.skipped[item] {
+			items = append(items, item)
+		}
+	}
+	im.close()
+
+	if len(items) == 0 {
+		toast("Nothing to import", toastWarn)
+		return
+	}
+
+	go func() {
+		var lastErr error
+		imported := 0
+		var last *core.Workspace
+		for _, item := range items {
+			w, err := a.api.Import(item, im.mode)
+			if err != nil {
+				lastErr = fmt.Errorf("failed to import %s: %w", core.HomeShortPath(item.Source), err)
+				continue
+			}
+			imported++
+			last = w
+		}
+
+		if last != nil {
+			a.refresh(last.Topic, last, nil)
+		}
+		a.ui.Update(func() {
+			if lastErr != nil {
+				toast(fmt.Sprintf("Imported %d of %d projects, %s", imported, len(items), lastErr), toastError)
+				return
+			}
+			toast(fmt.Sprintf("Imported %d projects", imported), toastInfo)
+		})
+	}()
+}
+
+func (im *Import) show() {
+	im.view.Clear()
+	im.table.Render(im.view)
+}
+
+func (im *Import) close() {
+	a.ui.DeleteView(im.view)
+	if im.prevView != nil {
+		a.ui.FocusView(im.prevView)
+	}
+}
@@ -15,6 +15,12 @@ type Info struct {
 	view          *tui.View
 	workspaceInfo *tui.TableRenderer[*core.Workspace]
 	sessionInfo   *tui.TableRenderer[*core.Session]
+
+	// description of the workspace shown, empty if it has none
+	description string
+
+	// directory the workspace shown links to, empty if it is not linked
+	target string
 }
 
 func newInfo() *Info {
@@ -34,17 +40,23 @@ func (w *Info) init() {
 		"Name",
 		"Topic",
 		"Last Modified",
+		"Branch",
+		"Git",
 		"Git Remote",
 	}, []float64{
+		0.15,
+		0.15,
+		0.25,
+		0.15,
+		0.10,
 		0.20,
-		0.20,
-		0.20,
-		0.40,
 	})
 	w.workspaceInfo.SetStyles([]color.Style{
 		workspaceNameColor,
 		topicNameColor,
 		timestampColor,
+		topicNameColor,
+		alternateSessionMarkerColor,
 		color.Question.Style,
 	})
 
@@ -121,8 +133,15 @@ func (i *Info) show(workspace *core.Workspace) {
 	if workspace == nil {
 		i.workspaceInfo.Clear()
 		i.sessionInfo.Clear()
+		i.description = ""
+		i.target = ""
 		return
 	}
+	i.description = workspace.Description()
+	i.target = ""
+	if workspace.Linked() {
+		i.target = workspace.RealPath()
+	}
 
 	// workspace info
 	remote, _ := workspace.GitRemote()
@@ -131,21 +150,42 @@ func (i *Info) show(workspace *core.Workspace) {
 	}
 
 	timeStr := fmt.Sprintf("%s (%s)", workspace.LastModified().Format(core.TimeFormat()), core.TimeAgo(workspace.LastModified()))
+	branch, status := gitStatusCols(workspace)
 	row := &tui.TableRow[*core.Workspace]{
 		Cols: []string{
 			workspace.Name,
 			workspace.Topic.Name,
 			timeStr,
+			branch,
+			status,
 			remote,
 		},
 		Value: workspace,
 	}
 	i.workspaceInfo.Fill([]*tui.TableRow[*core.Workspace]{row})
+	i.loadGitStatus(workspace)
 
 	session := a.api.Session(workspace)
 	i.showSession(session)
 }
 
+// Loads the git status of the workspace in the background and shows it again once loaded.
+func (i *Info) loadGitStatus(workspace *core.Workspace) {
+	go func() {
+		if !a.api.LoadGitStatuses(core.Workspaces{workspace}) {
+			return
+		}
+
+		a.ui.Update(func() {
+			// only show if the workspace is still the one displayed
+			_, row := i.workspaceInfo.SelectedRow()
+			if row != nil && row.Value.Path() == workspace.Path() {
+				i.show(workspace)
+			}
+		})
+	}()
+}
+
 func (w *Info) render() {
 	w.view.Clear()
 	a.ui.Resize(w.view, getViewPosition(w.view.Name()))
@@ -154,6 +194,17 @@ func (w *Info) render() {
 		w.workspaceInfo.RenderTable(w.view, func(i int, tr *tui.TableRow[*core.Workspace]) bool {
 			return false
 		}, nil)
+		// the link and the description share a line
+		line := make([]string, 0)
+		if w.target != "" {
+			line = append(line, linkedColor.Sprint("linked to "+core.HomeShortPath(w.target)))
+		}
+		if w.description != "" {
+			line = append(line, descriptionColor.Sprint(w.description))
+		}
+		if len(line) > 0 {
+			fmt.Fprintln(w.view, strings.Join(line, "  "))
+		}
 		fmt.Fprintln(w.view)
 	}
 
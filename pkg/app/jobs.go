@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GianlucaP106/mynav/pkg/core"
+	"github.com/GianlucaP106/mynav/pkg/tui"
+)
+
+// Jobs runs long tasks (e.g. git clones) in the background and shows their progress at the corner of the screen.
+type Jobs struct {
+	view *tui.View
+
+	// running jobs in start order
+	jobs []*Job
+
+	// waits for the running jobs to return
+	wg sync.WaitGroup
+
+	mu sync.Mutex
+}
+
+// Job is a background task that reports progress and can be cancelled.
+type Job struct {
+	// name displayed in the progress indicator
+	name string
+
+	// path of the workspace the job operates on, used to cancel the job from the workspaces view
+	path string
+
+	// last progress reported by the job
+	progress string
+
+	// last time the ui was updated with the progress
+	rendered time.Time
+
+	cancel context.CancelFunc
+	mu     sync.Mutex
+}
+
+// jobs magic numbers
+const (
+	// min delay between renders of the progress
+	jobRenderDelay = 100 * time.Millisecond
+
+	// width of the progress indicator
+	jobsViewWidth = 60
+
+	// max time to wait for the cancelled jobs when the app exits
+	jobsStopTimeout = 5 * time.Second
+)
+
+func newJobs() *Jobs {
+	return &Jobs{
+		jobs: make([]*Job, 0),
+	}
+}
+
+// Starts a job in the background.
+// run is cancelled through its context, done is called on the ui thread with the result of run.
+func (j *Jobs) start(name string, path string, run func(ctx context.Context, job *Job) error, done func(err error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		name:     name,
+		path:     path,
+		progress: "starting...",
+		cancel:   cancel,
+	}
+
+	j.mu.Lock()
+	j.jobs = append(j.jobs, job)
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		err := run(ctx, job)
+		cancel()
+		j.remove(job)
+		a.ui.Update(func() {
+			done(err)
+		})
+	}()
+}
+
+// Cancels the job running on the workspace. Returns false if there is none.
+func (j *Jobs) cancel(w *core.Workspace) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.jobs {
+		if job.path == w.Path() {
+			job.cancel()
+			return true
+		}
+	}
+
+	return false
+}
+
+// Cancels all the running jobs and waits for them to return, at most for timeout.
+func (j *Jobs) stop(timeout time.Duration) {
+	j.mu.Lock()
+	for _, job := range j.jobs {
+		job.cancel()
+	}
+	j.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+	}
+}
+
+// Returns if a job is running on the workspace.
+func (j *Jobs) running(w *core.Workspace) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.jobs {
+		if job.path == w.Path() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (j *Jobs) remove(job *Job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, other := range j.jobs {
+		if other == job {
+			j.jobs = append(j.jobs[:i], j.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Sets the progress of the job, the ui is updated at most every jobRenderDelay.
+func (job *Job) setProgress(progress string) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	job.progress = progress
+	if time.Since(job.rendered) < jobRenderDelay {
+		return
+	}
+
+	job.rendered = time.Now()
+	a.ui.Update(func() {})
+}
+
+func (job *Job) String() string {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.name + ": " + job.progress
+}
+
+// Renders the progress indicator, removing it when no job is running.
+func (j *Jobs) render() {
+	j.mu.Lock()
+	jobs := append([]*Job{}, j.jobs...)
+	j.mu.Unlock()
+
+	if len(jobs) == 0 {
+		if j.view != nil {
+			a.ui.DeleteView(j.view)
+			j.view = nil
+		}
+		return
+	}
+
+	maxX, maxY := a.ui.Size()
+	j.view = a.ui.SetView(tui.NewViewPosition(
+		JobsView,
+		maxX-jobsViewWidth-2,
+		maxY-len(jobs)-3,
+		maxX-2,
+		maxY-2,
+		0,
+	))
+	j.view.FrameRunes = tui.ThinFrame
+	j.view.Title = fmt.Sprintf("Jobs (%d)", len(jobs))
+	j.view.TitleColor = offTitleColor
+	j.view.FrameColor = offFrameColor
+
+	j.view.Clear()
+	for _, job := range jobs {
+		fmt.Fprintln(j.view, " "+tui.Pad(job.String(), jobsViewWidth-3))
+	}
+}
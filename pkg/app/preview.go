@@ -2,9 +2,10 @@ package app
 
 import (
 	"fmt"
+	"strings"
 	"sync"
-	"time"
 
+	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/GianlucaP106/mynav/pkg/core"
 	"github.com/GianlucaP106/mynav/pkg/tui"
 )
@@ -12,18 +13,26 @@ import (
 type Preview struct {
 	view *tui.View
 
-	// preview raw content
+	// pane contents, rendered for gocui
 	previews   []string
+	panes      []*gotmux.Pane
 	previewIdx int
 	previewMu  sync.RWMutex
 
+	// pane to show once the previews of its session are loaded
+	focusPane string
+
+	// if the notes of the workspace are shown instead of the panes of its session
+	notesMode bool
+
+	// workspace to show the notes of, with its notes
+	workspace *core.Workspace
+	notes     string
+
 	// session to show
-	// this needs to be kept track of because of periodic refresh
+	// this needs to be kept track of to refresh when tmux reports changes
 	session   *core.Session
 	sessionMu sync.RWMutex
-
-	// to kill the refresh routine
-	done chan bool
 }
 
 func newPreview() *Preview {
@@ -32,28 +41,10 @@ func newPreview() *Preview {
 }
 
 func (p *Preview) init(v *tui.View) {
-	p.done = make(chan bool)
 	p.view = v
 	p.view.Title = " Preview "
 	a.styleView(p.view)
-	p.setPreviews(nil)
-	go func() {
-		t := time.NewTicker(time.Second * 3)
-		for {
-			select {
-			case <-p.done:
-				return
-			case <-t.C:
-				if !a.attached.Load() {
-					p.refresh()
-					a.ui.Update(func() {
-						p.render()
-					})
-				}
-
-			}
-		}
-	}()
+	p.setPreviews(nil, nil)
 }
 
 func (p *Preview) setSession(session *core.Session) {
@@ -62,7 +53,7 @@ func (p *Preview) setSession(session *core.Session) {
 		p.session = nil
 		p.sessionMu.Unlock()
 
-		p.setPreviews(nil)
+		p.setPreviews(nil, nil)
 		return
 	}
 
@@ -70,9 +61,23 @@ func (p *Preview) setSession(session *core.Session) {
 	p.session = session
 	p.sessionMu.Unlock()
 
+	p.watch()
 	p.refresh()
 }
 
+// Asks tmux to report the output of the session if this preview is the active one.
+func (p *Preview) watch() {
+	if a.activePreview.Load() != p {
+		return
+	}
+
+	p.sessionMu.RLock()
+	session := p.session
+	p.sessionMu.RUnlock()
+
+	a.api.WatchSession(session)
+}
+
 func (p *Preview) refresh() {
 	p.sessionMu.RLock()
 
@@ -88,15 +93,21 @@ func (p *Preview) refresh() {
 
 	// collect all previews (one per pane)
 	previews := make([]string, 0)
+	previewPanes := make([]*gotmux.Pane, 0)
 	for _, w := range windows {
 		panes, _ := w.ListPanes()
 		for _, pane := range panes {
-			preview, _ := pane.Capture()
-			previews = append(previews, preview)
+			capture, err := a.api.CapturePane(pane)
+			if err != nil {
+				continue
+			}
+
+			previews = append(previews, tui.RenderTerminal(capture.Content, capture.CursorX, capture.CursorY))
+			previewPanes = append(previewPanes, pane)
 		}
 	}
 
-	p.setPreviews(previews)
+	p.setPreviews(previews, previewPanes)
 }
 
 func (p *Preview) render() {
@@ -109,6 +120,12 @@ func (p *Preview) render() {
 		a.ui.Resize(p.view, vp)
 	}
 
+	if p.notesMode {
+		p.renderNotes()
+		return
+	}
+	p.view.Title = " Preview "
+
 	if len(p.previews) == 0 {
 		p.view.Subtitle = ""
 		return
@@ -119,22 +136,119 @@ func (p *Preview) render() {
 	fmt.Fprintln(p.view, s)
 }
 
-func (p *Preview) setPreviews(previews []string) {
+func (p *Preview) setPreviews(previews []string, panes []*gotmux.Pane) {
 	p.previewMu.Lock()
 	defer p.previewMu.Unlock()
 
+	p.panes = panes
 	if len(previews) == 0 {
 		p.previewIdx = 0
 		p.previews = previews
 		return
 	}
 
+	if p.focusPane != "" {
+		for i, pane := range panes {
+			if pane.Id == p.focusPane {
+				p.previewIdx = i
+				p.focusPane = ""
+				break
+			}
+		}
+	}
+
 	if p.previewIdx >= len(previews) {
 		p.previewIdx = len(previews) - 1
 	}
 	p.previews = previews
 }
 
+// Renders the notes of the workspace, the preview lock must be held.
+func (p *Preview) renderNotes() {
+	p.view.Title = " Notes "
+	if p.workspace == nil {
+		p.view.Subtitle = ""
+		return
+	}
+
+	p.view.Subtitle = " " + p.workspace.ShortPath() + " "
+	if strings.TrimSpace(p.notes) == "" {
+		fmt.Fprintln(p.view, timestampColor.Sprint("No notes, press e in the Workspaces view to write some"))
+		return
+	}
+
+	fmt.Fprint(p.view, renderMarkdown(p.notes))
+}
+
+// Returns the markdown with its headings, list markers and code blocks highlighted.
+// Escape sequences and control characters are dropped so that the text cannot alter the ui.
+func renderMarkdown(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if (r < 0x20 && r != '\n' && r != '\t') || r == 0x7f {
+			return -1
+		}
+		return r
+	}, text)
+
+	lines := strings.Split(text, "\n")
+	code := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			code = !code
+			lines[i] = timestampColor.Sprint(line)
+		case code:
+			lines[i] = tagsColor.Sprint(line)
+		case strings.HasPrefix(trimmed, "#"):
+			lines[i] = topicNameColor.Sprint(line)
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines[i] = indent + sessionMarkerColor.Sprint(trimmed[:1]) + trimmed[1:]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Sets the workspace whose notes are shown in notes mode.
+func (p *Preview) setWorkspace(w *core.Workspace) {
+	notes := ""
+	if w != nil {
+		notes = w.Notes()
+	}
+
+	p.previewMu.Lock()
+	defer p.previewMu.Unlock()
+	p.workspace = w
+	p.notes = notes
+}
+
+// Switches between the panes of the session and the notes of the workspace.
+func (p *Preview) toggleNotes() {
+	p.previewMu.Lock()
+	defer p.previewMu.Unlock()
+	p.notesMode = !p.notesMode
+}
+
+// Shows the pane once the previews of its session are loaded.
+func (p *Preview) showPane(id string) {
+	p.previewMu.Lock()
+	defer p.previewMu.Unlock()
+	p.focusPane = id
+}
+
+// Returns the pane being previewed with its position among the panes of the session, nil if there is none.
+func (p *Preview) selectedPane() (*gotmux.Pane, int, int) {
+	p.previewMu.RLock()
+	defer p.previewMu.RUnlock()
+	if len(p.panes) == 0 {
+		return nil, 0, 0
+	}
+
+	return p.panes[p.previewIdx], p.previewIdx, len(p.panes)
+}
+
 func (p *Preview) increment() {
 	p.previewMu.Lock()
 	defer p.previewMu.Unlock()
@@ -155,7 +269,20 @@ func (p *Preview) decrement() {
 	}
 }
 
+// Opens the pane being previewed in the zoom view.
+func (p *Preview) zoom() {
+	p.sessionMu.RLock()
+	session := p.session
+	p.sessionMu.RUnlock()
+
+	pane, idx, count := p.selectedPane()
+	if session == nil || pane == nil {
+		return
+	}
+
+	zoom(session, pane, idx, count)
+}
+
 func (p *Preview) teardown() {
-	p.done <- true
 	a.ui.DeleteView(p.view)
 }
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"sync/atomic"
 	"time"
 )
 
@@ -136,3 +137,28 @@ func (r *Worker) handleDebounceLoad(dp *DebounceLoad) {
 		seen = true
 	}
 }
+
+// Throttler coalesces the calls made within a delay into a single call at the end of the delay.
+type Throttler struct {
+	// delay before running
+	d time.Duration
+
+	// if a call is already scheduled
+	pending atomic.Bool
+}
+
+func newThrottler(d time.Duration) *Throttler {
+	return &Throttler{d: d}
+}
+
+// Schedules f to run after the delay, unless a call is already scheduled. Runs in a separate goroutine.
+func (t *Throttler) Run(f func()) {
+	if !t.pending.CompareAndSwap(false, true) {
+		return
+	}
+
+	time.AfterFunc(t.d, func() {
+		t.pending.Store(false)
+		f()
+	})
+}
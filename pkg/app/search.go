@@ -2,7 +2,7 @@ package app
 
 import (
 	"fmt"
-	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/GianlucaP106/mynav/pkg/core"
@@ -19,6 +19,9 @@ type Search[T any] struct {
 	tableView      *tui.View
 	previewView    *Preview
 	table          *tui.TableRenderer[T]
+
+	// view whose sort mode orders the results, empty if the results have a fixed order
+	sortView string
 }
 
 // Params of the search dialog.
@@ -36,11 +39,14 @@ type SearchDialogConfig[T any] struct {
 	tableProportions    []float64
 	focusList           bool
 	enablePreview       bool
+
+	// if set, the results can be reordered with the sort mode persisted for this view
+	sortView string
 }
 
 // Opens the search dialog with the given params.
 func search[T any](params SearchDialogConfig[T]) *Search[T] {
-	s := &Search[T]{}
+	s := &Search[T]{sortView: params.sortView}
 	screenX, _ := a.ui.Size()
 
 	s.previewEnabled = params.enablePreview && screenX > 160
@@ -115,6 +121,7 @@ func search[T any](params SearchDialogConfig[T]) *Search[T] {
 	if s.previewEnabled {
 		s.previewView = newPreview()
 		s.previewView.init(a.ui.SetCenteredView(SearchListDialog3View, 100, 34, 0, 26))
+		a.setActivePreview(s.previewView)
 	}
 
 	update := func() {
@@ -190,6 +197,15 @@ func search[T any](params SearchDialogConfig[T]) *Search[T] {
 			help(s.tableView)
 		})
 
+	if params.sortView != "" {
+		a.ui.KeyBinding(s.tableView).
+			Set('o', "Cycle sort order", func() {
+				a.cycleSort(params.sortView)
+				s.table.Fill(params.onSearch(strings.TrimSpace(s.searchView.Buffer())))
+				update()
+			})
+	}
+
 	if params.focusList {
 		s.focusList()
 	} else {
@@ -204,7 +220,11 @@ func (s *Search[T]) renderTable() {
 	s.tableView.Clear()
 	row, _ := s.table.SelectedRow()
 	size := s.table.Size()
-	s.tableView.Subtitle = fmt.Sprintf(" %d / %d ", min(row+1, size), size)
+	if s.sortView != "" {
+		s.tableView.Subtitle = fmt.Sprintf(" %s | %d / %d ", sortLabel(s.sortView), min(row+1, size), size)
+	} else {
+		s.tableView.Subtitle = fmt.Sprintf(" %d / %d ", min(row+1, size), size)
+	}
 	s.table.Render(s.tableView)
 }
 
@@ -229,6 +249,7 @@ func (s *Search[T]) close() {
 	a.ui.DeleteView(s.bgView)
 	if s.previewEnabled {
 		s.previewView.teardown()
+		a.setActivePreview(a.preview)
 	}
 }
 
@@ -244,105 +265,98 @@ func newGlobalSearch() *GlobalSearch {
 }
 
 func (g *GlobalSearch) init() {
-	useFzf := core.IsFzfInstalled()
-	if !useFzf {
-		toast("install fzf it for a better experience", toastWarn)
-	}
-
-	const workspacePrefix = "--workspace--"
-	const sessionPrefix = "--session--"
-
-	allNames := []string{}
-	allWorkspaces := a.api.AllWorkspaces().Sorted()
-	for _, w := range allWorkspaces {
-		allNames = append(allNames, fmt.Sprintf("%s%s", workspacePrefix, w.ShortPath()))
-	}
-
-	allSessions := a.api.AllSessions()
-	for _, s := range allSessions {
-		allNames = append(allNames, fmt.Sprintf("%s%s", sessionPrefix, s.Name))
-	}
+	workspaces := a.api.AllWorkspaces()
+	sessions := a.api.AllSessions()
 
 	searchFor := func(s string) []*tui.TableRow[SearchItem] {
-		foundItems := make([]SearchItem, 0)
-		if useFzf {
-			found := core.FuzzyFind(allNames, s)
-			for _, item := range found {
-				if item == "" {
-					continue
-				}
-
-				switch {
-				case strings.HasPrefix(item, workspacePrefix):
-					i := strings.TrimPrefix(item, workspacePrefix)
-					w := a.api.Workspace(i)
-					if w != nil {
-						foundItems = append(foundItems, SearchItem{
-							workspace: w,
-						})
-					}
-				case strings.HasPrefix(item, sessionPrefix):
-					i := strings.TrimPrefix(item, sessionPrefix)
-					session, _ := a.api.SessionByName(i)
-					if session != nil {
-						foundItems = append(foundItems, SearchItem{
-							session: session,
-						})
-					}
-				}
+		// tag and topic filters narrow the candidates, the rest of the query is matched against their names
+		query := core.ParseWorkspaceQuery(s)
+		items := make([]SearchItem, 0)
+		for _, item := range g.items(workspaces, sessions, a.api.SortMode(SearchListDialog2View)) {
+			w := item.workspace
+			if item.session != nil {
+				w = item.session.Workspace
 			}
-		} else {
-			for _, workspace := range allWorkspaces {
-				if strings.Contains(workspace.Name, s) {
-					foundItems = append(foundItems, SearchItem{
-						workspace: workspace,
-					})
-				}
+
+			if query.Filtered() && (w == nil || !query.Matches(w, a.api.WorkspaceTags(w))) {
+				continue
 			}
-			for _, session := range allSessions {
-				if strings.Contains(session.Name, s) {
-					foundItems = append(foundItems, SearchItem{
-						session: session,
-					})
-				}
+			items = append(items, item)
+		}
+
+		// candidates, workspaces by short path and sessions by name
+		names := make([]string, len(items))
+		for i, item := range items {
+			if item.session != nil {
+				names[i] = item.session.Name
+			} else {
+				names[i] = item.workspace.ShortPath()
 			}
 		}
 
+		// results that score the same keep the order of the sort mode
+		matches := core.FuzzyFilter(names, query.Text)
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].Score != matches[j].Score {
+				return matches[i].Score > matches[j].Score
+			}
+			return matches[i].Index < matches[j].Index
+		})
+
 		tableRows := make([]*tui.TableRow[SearchItem], 0)
-		for _, item := range foundItems {
+		for _, match := range matches {
+			item := items[match.Index]
+			positions := match.Positions
 			cols := []string{}
 			styles := []color.Style{}
 			switch {
 			case item.session != nil:
 				name := item.session.Name
 				if parts := strings.Split(name, "/"); len(parts) > 3 {
-					lastParts := parts[len(parts)-3:]
-					lastParts = append([]string{".../"}, lastParts...)
-					name = filepath.Join(lastParts...)
+					// only the end of long names is shown, the matched positions are moved along
+					short := ".../" + strings.Join(parts[len(parts)-3:], "/")
+					offset := len([]rune(name)) - len([]rune(short))
+					shifted := make([]int, 0)
+					for _, p := range positions {
+						if p-offset >= len([]rune(".../")) {
+							shifted = append(shifted, p-offset)
+						}
+					}
+					name, positions = short, shifted
+				}
+
+				tags := ""
+				if item.session.Workspace != nil {
+					tags = strings.Join(a.api.WorkspaceTags(item.session.Workspace), ",")
 				}
 
 				cols = []string{
 					name,
 					"Session",
+					tags,
 				}
 				styles = []color.Style{
 					workspaceNameColor,
 					sessionMarkerColor,
+					tagsColor,
 				}
 			case item.workspace != nil:
 				cols = []string{
 					item.workspace.ShortPath(),
 					"Workspace",
+					strings.Join(a.api.WorkspaceTags(item.workspace), ","),
 				}
 				styles = []color.Style{
 					workspaceNameColor,
 					alternateSessionMarkerColor,
+					tagsColor,
 				}
 			}
 			tableRows = append(tableRows, &tui.TableRow[SearchItem]{
-				Cols:   cols,
-				Value:  item,
-				Styles: styles,
+				Cols:    cols,
+				Value:   item,
+				Styles:  styles,
+				Matches: [][]int{positions},
 			})
 		}
 		return tableRows
@@ -384,14 +398,56 @@ func (g *GlobalSearch) init() {
 		tableTitles: []string{
 			"Name",
 			"Type",
+			"Tags",
 		}, tableProportions: []float64{
-			0.6,
-			0.4,
+			0.45,
+			0.25,
+			0.3,
 		},
 		colStyles: []color.Style{
 			workspaceNameColor,
 			sessionMarkerColor,
+			tagsColor,
 		},
 		enablePreview: true,
+		sortView:      SearchListDialog2View,
 	})
 }
+
+// Returns the search candidates ordered by the sort mode.
+// Workspaces come before sessions, unless sorting by frecency which ranks them together.
+func (g *GlobalSearch) items(workspaces core.Workspaces, sessions []*core.Session, mode core.SortMode) []SearchItem {
+	items := make([]SearchItem, 0)
+	scores := make([]float64, 0)
+	// the lists are sorted in place and may be in use by a previous search
+	workspaces = append(core.Workspaces{}, workspaces...)
+	sessions = append([]*core.Session{}, sessions...)
+
+	for _, w := range a.api.SortWorkspaces(workspaces, mode) {
+		items = append(items, SearchItem{workspace: w})
+		scores = append(scores, a.api.WorkspaceFrecency(w))
+	}
+
+	for _, s := range a.api.SortSessions(sessions, mode) {
+		items = append(items, SearchItem{session: s})
+		scores = append(scores, a.api.SessionFrecency(s))
+	}
+
+	if mode == core.SortFrecency {
+		order := make([]int, len(items))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return scores[order[i]] > scores[order[j]]
+		})
+
+		sorted := make([]SearchItem, len(items))
+		for i, idx := range order {
+			sorted[i] = items[idx]
+		}
+		items = sorted
+	}
+
+	return items
+}
@@ -2,10 +2,10 @@ package app
 
 import (
 	"fmt"
-	"sort"
 	"strconv"
-	"time"
+	"sync"
 
+	"github.com/GianlucaP106/gotmux/gotmux"
 	"github.com/GianlucaP106/mynav/pkg/core"
 	"github.com/GianlucaP106/mynav/pkg/tui"
 	"github.com/awesome-gocui/gocui"
@@ -13,30 +13,119 @@ import (
 )
 
 // Sessions view displaying active workspace sessions.
+// Sessions can be expanded into a tree of their windows and panes.
 type Sessions struct {
 	view  *tui.View
-	table *tui.TableRenderer[*core.Session]
+	table *tui.TableRenderer[*sessionNode]
+
+	// expanded sessions (by name) and windows (by id)
+	expanded   map[string]bool
+	expandedMu sync.RWMutex
 
 	// loading flag to display loading (not atomic as it should only be touched in the mainloop)
 	loading bool
 }
 
+// Styles of the window and pane rows of the session tree.
+var (
+	windowRowStyles = []color.Style{
+		topicNameColor,
+		sessionMarkerColor,
+		sessionMarkerColor,
+		alternateSessionMarkerColor,
+	}
+	paneRowStyles = []color.Style{
+		color.New(color.FgCyan),
+		timestampColor,
+		timestampColor,
+		timestampColor,
+	}
+)
+
+// sessionNode is a row of the session tree, a session or one of its windows or panes.
+type sessionNode struct {
+	session *core.Session
+
+	// set for window and pane rows
+	window *gotmux.Window
+
+	// set for pane rows
+	pane *gotmux.Pane
+}
+
 func newSessionsView() *Sessions {
-	s := &Sessions{}
+	s := &Sessions{
+		expanded: make(map[string]bool),
+	}
 	return s
 }
 
 func (s *Sessions) selected() *core.Session {
-	_, session := s.table.SelectedRow()
-	if session != nil {
-		return session.Value
+	node := s.selectedNode()
+	if node != nil {
+		return node.session
+	}
+	return nil
+}
+
+func (s *Sessions) selectedNode() *sessionNode {
+	_, row := s.table.SelectedRow()
+	if row != nil {
+		return row.Value
 	}
 	return nil
 }
 
 func (s *Sessions) selectSession(session *core.Session) {
-	s.table.SelectRowByValue(func(session2 *core.Session) bool {
-		return session2.Name == session.Name
+	s.table.SelectRowByValue(func(node *sessionNode) bool {
+		return node.window == nil && node.session.Name == session.Name
+	})
+}
+
+// Refreshes the sessions and selects the row of the window, or of its session if the window is gone.
+func (s *Sessions) refreshWindow(session *core.Session, windowId string) {
+	a.worker.Queue(func() {
+		s.refresh()
+		s.selectSession(session)
+		s.table.SelectRowByValue(func(node *sessionNode) bool {
+			return node.pane == nil && node.window != nil && node.window.Id == windowId
+		})
+		s.refreshPreview()
+		a.ui.Update(func() {
+			s.showInfo()
+			s.render()
+			a.preview.render()
+		})
+	})
+}
+
+func (s *Sessions) isExpanded(key string) bool {
+	s.expandedMu.RLock()
+	defer s.expandedMu.RUnlock()
+	return s.expanded[key]
+}
+
+// Expands or collapses the selected session or window.
+func (s *Sessions) toggle() {
+	node := s.selectedNode()
+	if node == nil || node.pane != nil {
+		return
+	}
+
+	key := node.session.Name
+	if node.window != nil {
+		key = node.window.Id
+	}
+
+	s.expandedMu.Lock()
+	s.expanded[key] = !s.expanded[key]
+	s.expandedMu.Unlock()
+
+	a.worker.Queue(func() {
+		s.refresh()
+		a.ui.Update(func() {
+			s.render()
+		})
 	})
 }
 
@@ -60,13 +149,25 @@ func (s *Sessions) showInfo() {
 }
 
 func (s *Sessions) refreshPreview() {
-	session := s.selected()
-	if session == nil {
+	node := s.selectedNode()
+	if node == nil {
+		a.preview.setWorkspace(nil)
 		a.preview.setSession(nil)
 		return
 	}
+	a.preview.setWorkspace(node.session.Workspace)
+
+	// show the pane (or the active pane of the window) of the row
+	switch {
+	case node.pane != nil:
+		a.preview.showPane(node.pane.Id)
+	case node.window != nil:
+		if pane, err := a.api.ActivePane(node.window); err == nil {
+			a.preview.showPane(pane.Id)
+		}
+	}
 
-	a.preview.setSession(session)
+	a.preview.setSession(node.session)
 }
 
 func (s *Sessions) focus() {
@@ -85,35 +186,86 @@ func (s *Sessions) refreshDown() {
 }
 
 func (s *Sessions) refresh() {
-	sessions := a.api.AllSessions()
-	// sort by last attached
-	sort.Slice(sessions, func(i, j int) bool {
-		t1 := core.UnixTime(sessions[i].LastAttached)
-		t2 := core.UnixTime(sessions[j].LastAttached)
-		return t1.After(t2)
-	})
+	sessions := a.api.SortSessions(a.api.AllSessions(), a.api.SortMode(SessionsView))
 
 	// fill table
-	tableRows := make([]*tui.TableRow[*core.Session], 0)
-	for _, s := range sessions {
-		timeStr := core.TimeAgo(core.UnixTime(s.LastAttached))
+	tableRows := make([]*tui.TableRow[*sessionNode], 0)
+	for _, session := range sessions {
+		timeStr := core.TimeAgo(core.UnixTime(session.LastAttached))
 		var wMarker string
-		if s.Workspace != nil {
+		if session.Workspace != nil {
 			wMarker = "Yes"
 		}
-		tableRows = append(tableRows, &tui.TableRow[*core.Session]{
+
+		expanded := s.isExpanded(session.Name)
+		tableRows = append(tableRows, &tui.TableRow[*sessionNode]{
 			Cols: []string{
-				s.DisplayName(),
-				strconv.Itoa(s.Windows),
+				treeMarker(expanded) + session.DisplayName(),
+				strconv.Itoa(session.Windows),
 				wMarker,
 				timeStr,
 			},
-			Value: s,
+			Value: &sessionNode{session: session},
 		})
+
+		if expanded {
+			tableRows = append(tableRows, s.windowRows(session)...)
+		}
 	}
 	s.table.Fill(tableRows)
 }
 
+// Returns the rows of the windows of the session, followed by their panes if they are expanded.
+func (s *Sessions) windowRows(session *core.Session) []*tui.TableRow[*sessionNode] {
+	rows := make([]*tui.TableRow[*sessionNode], 0)
+	windows, _ := a.api.SessionWindows(session)
+	for _, w := range windows {
+		expanded := s.isExpanded(w.Id)
+		active := ""
+		if w.Active {
+			active = "Active"
+		}
+		rows = append(rows, &tui.TableRow[*sessionNode]{
+			Cols: []string{
+				"  " + treeMarker(expanded) + fmt.Sprintf("%d: %s", w.Index, w.Name),
+				strconv.Itoa(w.Panes),
+				"",
+				active,
+			},
+			Value:  &sessionNode{session: session, window: w},
+			Styles: windowRowStyles,
+		})
+
+		if !expanded {
+			continue
+		}
+
+		panes, _ := a.api.WindowPanes(w)
+		for _, p := range panes {
+			rows = append(rows, &tui.TableRow[*sessionNode]{
+				Cols: []string{
+					fmt.Sprintf("      %d: %s", p.Index, p.CurrentCommand),
+					strconv.Itoa(int(p.Pid)),
+					"",
+					core.HomeShortPath(p.CurrentPath),
+				},
+				Value:  &sessionNode{session: session, window: w, pane: p},
+				Styles: paneRowStyles,
+			})
+		}
+	}
+
+	return rows
+}
+
+// Returns the marker of an expandable row.
+func treeMarker(expanded bool) string {
+	if expanded {
+		return "- "
+	}
+	return "+ "
+}
+
 func (s *Sessions) render() {
 	s.view.Clear()
 	a.ui.Resize(s.view, getViewPosition(s.view.Name()))
@@ -121,7 +273,7 @@ func (s *Sessions) render() {
 	// update page row marker
 	row, _ := s.table.SelectedRow()
 	size := s.table.Size()
-	s.view.Subtitle = fmt.Sprintf(" %d / %d ", min(row+1, size), size)
+	s.view.Subtitle = fmt.Sprintf(" %s | %d / %d ", sortLabel(SessionsView), min(row+1, size), size)
 
 	if s.getLoading() {
 		fmt.Fprintln(s.view, "Loading...")
@@ -130,34 +282,80 @@ func (s *Sessions) render() {
 
 	// renders table and updates the last modified time
 	isFocused := a.ui.IsFocused(s.view)
-	s.table.RenderTable(s.view, func(i int, tr *tui.TableRow[*core.Session]) bool {
+	s.table.RenderTable(s.view, func(i int, tr *tui.TableRow[*sessionNode]) bool {
 		return isFocused
-	}, func(i int, tr *tui.TableRow[*core.Session]) {
-		newTime := core.TimeAgo(core.UnixTime(tr.Value.LastAttached))
+	}, func(i int, tr *tui.TableRow[*sessionNode]) {
+		if tr.Value.window != nil {
+			return
+		}
+
+		newTime := core.TimeAgo(core.UnixTime(tr.Value.session.LastAttached))
 		tr.Cols[len(tr.Cols)-1] = newTime
 	})
 }
 
 func (s *Sessions) attach(session *core.Session) {
-	if core.IsTmuxSession() {
-		toast("A tmux session is already active", toastWarn)
-		return
-	}
-
-	start := time.Now()
-	err := a.runAction(func() error {
-		return session.Attach()
+	a.openSession(session.DisplayName(), func() error {
+		return a.api.AttachSession(session)
 	})
+	a.refresh(nil, nil, session)
+}
 
+// Attaches to the session of the row, on its window or pane.
+func (s *Sessions) attachNode(node *sessionNode) {
+	var err error
+	switch {
+	case node.pane != nil:
+		err = a.api.SelectPane(node.pane)
+	case node.window != nil:
+		err = a.api.SelectWindow(node.window)
+	}
 	if err != nil {
 		toast(err.Error(), toastError)
-	} else {
-		timeTaken := time.Since(start)
-		s := fmt.Sprintf("Detached session %s - %s active", session.DisplayName(), core.TimeDeltaStr(timeTaken))
-		toast(s, toastInfo)
+		return
 	}
 
-	a.refresh(nil, nil, session)
+	s.attach(node.session)
+}
+
+// Kills the session, window or pane of the row after confirmation.
+func (s *Sessions) kill(node *sessionNode) {
+	var name, question string
+	var kill func() error
+	switch {
+	case node.pane != nil:
+		name = fmt.Sprintf("pane %d of window %s", node.pane.Index, node.window.Name)
+		question = fmt.Sprintf("Are you sure you want to kill %s?", name)
+		kill = func() error {
+			return a.api.KillPane(node.session, node.window, node.pane)
+		}
+	case node.window != nil:
+		name = "window " + node.window.Name
+		question = fmt.Sprintf("Are you sure you want to kill %s of %s?", name, node.session.DisplayName())
+		kill = func() error {
+			return a.api.KillWindow(node.session, node.window)
+		}
+	default:
+		name = "session " + node.session.DisplayName()
+		question = fmt.Sprintf("Are you sure you want to delete session for %s?", node.session.DisplayName())
+		kill = func() error {
+			return a.api.KillSession(node.session)
+		}
+	}
+
+	alert(func(b bool) {
+		if !b {
+			return
+		}
+
+		if err := kill(); err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		a.refresh(nil, nil, node.session)
+		toast("Killed "+name, toastInfo)
+	}, question)
 }
 
 func (s *Sessions) init() {
@@ -184,7 +382,7 @@ func (s *Sessions) init() {
 		sessionMarkerColor,
 		timestampColor,
 	}
-	s.table = tui.NewTableRenderer[*core.Session]()
+	s.table = tui.NewTableRenderer[*sessionNode]()
 	s.table.Init(sizeX, sizeY, titles, proportions)
 	s.table.SetStyles(styles)
 
@@ -209,32 +407,64 @@ func (s *Sessions) init() {
 			s.table.Bottom()
 			s.refreshDown()
 		}).
-		Set(gocui.KeyEnter, "Open Session", func() {
-			session := s.selected()
-			if session == nil {
+		Set(gocui.KeyEnter, "Open session, window or pane", func() {
+			node := s.selectedNode()
+			if node == nil {
 				return
 			}
 
-			s.attach(session)
+			s.attachNode(node)
 		}).
-		Set('D', "Kill session", func() {
-			session := s.selected()
-			if session == nil {
+		Set('e', "Expand/collapse", s.toggle).
+		Set(gocui.KeySpace, "Expand/collapse", s.toggle).
+		Set('D', "Kill session, window or pane", func() {
+			node := s.selectedNode()
+			if node == nil {
 				return
 			}
-			alert(func(b bool) {
-				if !b {
+
+			s.kill(node)
+		}).
+		Set('r', "Rename window", func() {
+			node := s.selectedNode()
+			if node == nil || node.window == nil {
+				toast("Select a window to rename", toastWarn)
+				return
+			}
+
+			editor(func(name string) {
+				if err := a.api.RenameWindow(node.window, name); err != nil {
+					toast(err.Error(), toastError)
 					return
 				}
 
-				if err := session.Kill(); err != nil {
+				s.refreshWindow(node.session, node.window.Id)
+				toast("Renamed window to "+name, toastInfo)
+			}, func() {}, "Window name", smallEditorSize, node.window.Name)
+		}).
+		Set('n', "New window", func() {
+			session := s.selected()
+			if session == nil {
+				return
+			}
+
+			form(func(values []string) {
+				w, err := a.api.NewWindow(session, values[0], values[1])
+				if err != nil {
 					toast(err.Error(), toastError)
 					return
 				}
 
-				a.refresh(nil, nil, session)
-				toast("Killed session "+session.DisplayName(), toastInfo)
-			}, fmt.Sprintf("Are you sure you want to delete session for %s?", session.DisplayName()))
+				s.expandedMu.Lock()
+				s.expanded[session.Name] = true
+				s.expandedMu.Unlock()
+
+				s.refreshWindow(session, w.Id)
+				toast("Created window "+w.Name, toastInfo)
+			}, func() {}, "New window in "+session.DisplayName(),
+				textField("Name (automatic if empty)", ""),
+				textField("Command (shell if empty)", ""),
+			)
 		}).
 		Set('w', "Go to workspace", func() {
 			session := s.selected()
@@ -252,6 +482,32 @@ func (s *Sessions) init() {
 			a.workspaces.selectWorkspace(session.Workspace)
 			a.workspaces.focus()
 		}).
+		Set('S', "Save sessions snapshot", func() {
+			a.worker.Queue(func() {
+				count, err := a.api.SaveSessions()
+				a.ui.Update(func() {
+					if err != nil {
+						toast(err.Error(), toastError)
+						return
+					}
+
+					toast(fmt.Sprintf("Saved %d sessions", count), toastInfo)
+				})
+			})
+		}).
+		Set('R', "Restore saved sessions", func() {
+			a.worker.Queue(func() {
+				count, err := a.api.RestoreSessions()
+				a.ui.Update(func() {
+					if err != nil {
+						toast(err.Error(), toastError)
+					} else {
+						toast(fmt.Sprintf("Restored %d sessions", count), toastInfo)
+					}
+				})
+				a.refreshAll()
+			})
+		}).
 		Set('a', "Create a Sesssion", func() {
 			editor(func(name string) {
 				session, err := a.api.NewSession(name)
@@ -262,6 +518,11 @@ func (s *Sessions) init() {
 				s.attach(session)
 			}, func() {}, "Session Name", smallEditorSize, "")
 		}).
+		Set('o', "Cycle sort order", func() {
+			session := s.selected()
+			a.cycleSort(SessionsView)
+			a.refresh(nil, nil, session)
+		}).
 		Set('h', "Focus workspaces view", func() {
 			a.workspaces.focus()
 		}).
@@ -2,7 +2,10 @@ package app
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/GianlucaP106/mynav/pkg/core"
 	"github.com/GianlucaP106/mynav/pkg/tui"
@@ -10,13 +13,20 @@ import (
 	"github.com/gookit/color"
 )
 
+// Topics view displaying the topics as a tree of their sub-topics.
 type Topics struct {
 	view  *tui.View
 	table *tui.TableRenderer[*core.Topic]
+
+	// collapsed topics (by name), topics are expanded by default
+	collapsed   map[string]bool
+	collapsedMu sync.RWMutex
 }
 
 func newTopicsView() *Topics {
-	t := &Topics{}
+	t := &Topics{
+		collapsed: make(map[string]bool),
+	}
 	return t
 }
 
@@ -52,15 +62,34 @@ func (tv *Topics) refreshDown() {
 }
 
 func (tv *Topics) refresh() {
-	topics := a.api.Topics().Sorted()
+	topics := a.api.SortTopics(a.api.Topics(), a.api.SortMode(TopicView)).Tree()
+
+	parents := map[string]bool{}
+	for _, topic := range topics {
+		parents[topic.Parent()] = true
+	}
 
 	tableRows := make([]*tui.TableRow[*core.Topic], 0)
 	for _, topic := range topics {
+		if tv.isHidden(topic) {
+			continue
+		}
+
+		// the tree markers are only shown once there are sub-topics
+		name := topic.Name
+		if len(parents) > 1 {
+			marker := "  "
+			if parents[topic.Name] {
+				marker = treeMarker(!tv.isCollapsed(topic.Name))
+			}
+			name = strings.Repeat("  ", topic.Depth()) + marker + topic.BaseName()
+		}
+
 		topicWorkspaces := a.api.Workspaces(topic)
 		timeStr := core.TimeAgo(topic.LastModified())
 		tableRows = append(tableRows, &tui.TableRow[*core.Topic]{
 			Cols: []string{
-				topic.Name,
+				name,
 				strconv.Itoa(len(topicWorkspaces)),
 				timeStr,
 			},
@@ -71,6 +100,42 @@ func (tv *Topics) refresh() {
 	tv.table.Fill(tableRows)
 }
 
+func (tv *Topics) isCollapsed(name string) bool {
+	tv.collapsedMu.RLock()
+	defer tv.collapsedMu.RUnlock()
+	return tv.collapsed[name]
+}
+
+// Returns if one of the parent topics of the topic is collapsed.
+func (tv *Topics) isHidden(t *core.Topic) bool {
+	for parent := t.Parent(); parent != "" && parent != "."; parent = filepath.Dir(parent) {
+		if tv.isCollapsed(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Expands or collapses the selected topic.
+func (tv *Topics) toggle() {
+	t := tv.selected()
+	if t == nil {
+		return
+	}
+
+	tv.collapsedMu.Lock()
+	tv.collapsed[t.Name] = !tv.collapsed[t.Name]
+	tv.collapsedMu.Unlock()
+
+	a.worker.Queue(func() {
+		tv.refresh()
+		tv.selectTopic(t)
+		a.ui.Update(func() {
+			tv.render()
+		})
+	})
+}
+
 func (tv *Topics) selected() *core.Topic {
 	_, t := tv.table.SelectedRow()
 	if t != nil {
@@ -79,7 +144,19 @@ func (tv *Topics) selected() *core.Topic {
 	return nil
 }
 
+// Selects the topic, expanding its parent topics if it is hidden.
 func (tv *Topics) selectTopic(t *core.Topic) {
+	if tv.isHidden(t) {
+		tv.collapsedMu.Lock()
+		for name := range tv.collapsed {
+			if name != t.Name && strings.HasPrefix(t.Name, name+"/") {
+				delete(tv.collapsed, name)
+			}
+		}
+		tv.collapsedMu.Unlock()
+		tv.refresh()
+	}
+
 	tv.table.SelectRowByValue(func(t2 *core.Topic) bool {
 		return t2.Name == t.Name
 	})
@@ -93,7 +170,7 @@ func (tv *Topics) render() {
 	// update row marker
 	row, _ := tv.table.SelectedRow()
 	size := tv.table.Size()
-	tv.view.Subtitle = fmt.Sprintf(" %d / %d ", min(row+1, size), size)
+	tv.view.Subtitle = fmt.Sprintf(" %s | %d / %d ", sortLabel(TopicView), min(row+1, size), size)
 
 	// renders table and updates the last modified time
 	tv.table.RenderTable(tv.view, func(_ int, _ *tui.TableRow[*core.Topic]) bool {
@@ -169,6 +246,26 @@ func (tv *Topics) init() {
 				toast("Created topic "+topic.Name, toastInfo)
 			}, func() {}, "Topic name", smallEditorSize, "")
 		}).
+		Set('A', "Create a sub-topic", func() {
+			t := tv.selected()
+			if t == nil {
+				return
+			}
+
+			editor(func(s string) {
+				topic, err := a.api.NewTopic(t.Name + "/" + s)
+				if err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				a.refresh(topic, nil, nil)
+				toast("Created topic "+topic.Name, toastInfo)
+			}, func() {}, "Sub-topic name in "+t.Name, smallEditorSize, "")
+		}).
+		Set('I', "Import a directory", importWizard).
+		Set('e', "Expand/collapse", tv.toggle).
+		Set(gocui.KeySpace, "Expand/collapse", tv.toggle).
 		Set('r', "Rename topic", func() {
 			t := tv.selected()
 			if t == nil {
@@ -190,17 +287,22 @@ func (tv *Topics) init() {
 			if t == nil {
 				return
 			}
-			alert(func(b bool) {
-				if !b {
-					return
-				}
+			confirmDelete(func() {
 				if err := a.api.DeleteTopic(t); err != nil {
 					toast(err.Error(), toastError)
+					return
 				}
 
 				a.refreshAll()
 				toast("Deleted topic "+t.Name, toastInfo)
-			}, fmt.Sprintf("Are you sure you want to delete topic %s? All its content will be deleted.", t.Name))
+			}, t.Name, fmt.Sprintf("Are you sure you want to delete topic %s? It is moved to the trash and kept %s.", t.Name, trashRetention()), func() ([]*core.GitRisk, error) {
+				return a.api.TopicRisks(t)
+			})
+		}).
+		Set('o', "Cycle sort order", func() {
+			t := tv.selected()
+			a.cycleSort(TopicView)
+			a.refresh(t, nil, nil)
 		}).
 		Set('l', "Focus workspace view", func() {
 			a.workspaces.focus()
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/GianlucaP106/mynav/pkg/core"
+	"github.com/GianlucaP106/mynav/pkg/tui"
+	"github.com/awesome-gocui/gocui"
+	"github.com/gookit/color"
+)
+
+// Trash is a dialog listing the deleted topics and workspaces, which can be restored or purged.
+type Trash struct {
+	view *tui.View
+
+	// table renderer
+	table *tui.TableRenderer[*core.TrashItem]
+
+	// view focused before the dialog was opened
+	prevView *tui.View
+}
+
+// Returns how long deleted items are kept in the trash as shown to the user (e.g. "30 days").
+func trashRetention() string {
+	if retention := a.api.TrashRetention(); retention > 0 {
+		return fmt.Sprintf("%d days", int(retention.Hours()/24))
+	}
+	return "forever"
+}
+
+func trash() *Trash {
+	t := &Trash{}
+	t.prevView = a.ui.FocusedView()
+	t.view = a.ui.SetCenteredView(TrashDialog, 100, 20, 0, 0)
+	a.styleView(t.view)
+	t.view.TitleColor = onTitleColor
+	t.view.FrameColor = onFrameColor
+
+	t.view.Title = " Trash "
+	t.view.Subtitle = fmt.Sprintf(" Items are kept %s ", trashRetention())
+
+	x, y := t.view.Size()
+	t.table = tui.NewTableRenderer[*core.TrashItem]()
+	t.table.Init(x, y, []string{"Kind", "Deleted From", "Deleted"}, []float64{0.2, 0.5, 0.3})
+	t.table.SetStyles([]color.Style{
+		color.New(color.FgYellow, color.Bold),
+		workspaceNameColor,
+		timestampColor,
+	})
+
+	down := func() {
+		t.table.Down()
+		t.show()
+	}
+	up := func() {
+		t.table.Up()
+		t.show()
+	}
+	restore := func() {
+		item := t.selected()
+		if item == nil {
+			return
+		}
+
+		if err := a.api.RestoreTrashItem(item); err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		t.refresh()
+		a.refreshAll()
+		toast("Restored "+item.Origin, toastInfo)
+	}
+	a.ui.KeyBinding(t.view).
+		Set('j', "Move down", down).
+		Set('k', "Move up", up).
+		Set(gocui.KeyArrowDown, "Move down", down).
+		Set(gocui.KeyArrowUp, "Move up", up).
+		Set(gocui.KeyEnter, "Restore item", restore).
+		Set('r', "Restore item", restore).
+		Set('D', "Delete item permanently", func() {
+			item := t.selected()
+			if item == nil {
+				return
+			}
+
+			alert(func(b bool) {
+				if !b {
+					return
+				}
+
+				if err := a.api.PurgeTrashItem(item); err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				t.refresh()
+				toast("Permanently deleted "+item.Origin, toastInfo)
+			}, fmt.Sprintf("Are you sure you want to permanently delete %s?", item.Origin))
+		}).
+		Set('E', "Empty trash", func() {
+			if t.table.Size() == 0 {
+				return
+			}
+
+			alert(func(b bool) {
+				if !b {
+					return
+				}
+
+				count, err := a.api.EmptyTrash()
+				t.refresh()
+				if err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				toast(fmt.Sprintf("Permanently deleted %d items", count), toastInfo)
+			}, "Are you sure you want to permanently delete all the items in the trash?")
+		}).
+		Set('T', "Close trash", t.close).
+		Set(gocui.KeyEsc, "Close trash", t.close).
+		Set('?', "Toggle cheatsheet", func() {
+			help(t.view)
+		})
+
+	t.refresh()
+	a.ui.FocusView(t.view)
+	return t
+}
+
+// Loads the items in the trash.
+func (t *Trash) refresh() {
+	rows := make([]*tui.TableRow[*core.TrashItem], 0)
+	for _, item := range a.api.TrashItems() {
+		rows = append(rows, &tui.TableRow[*core.TrashItem]{
+			Cols: []string{
+				item.Kind,
+				item.Origin,
+				core.TimeAgo(item.DeletedAt),
+			},
+			Value: item,
+		})
+	}
+
+	t.table.Fill(rows)
+	t.show()
+}
+
+func (t *Trash) selected() *core.TrashItem {
+	_, row := t.table.SelectedRow()
+	if row == nil {
+		return nil
+	}
+
+	return row.Value
+}
+
+func (t *Trash) show() {
+	t.view.Clear()
+	t.table.Render(t.view)
+}
+
+func (t *Trash) close() {
+	a.ui.DeleteView(t.view)
+	if t.prevView != nil {
+		a.ui.FocusView(t.prevView)
+	}
+}
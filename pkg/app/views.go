@@ -16,14 +16,20 @@ const (
 	Header2View       = "Header2View"
 	Header3View       = "Header3View"
 	Header4View       = "Header4View"
+	PinnedView        = "PinnedView"
 )
 
 // Dialogs.
 const (
 	EditorDialog           = "EditorDialog"
+	FormDialog             = "FormDialog"
 	ConfirmationDialog     = "ConfirmationDialog"
 	ToastDialog            = "ToastDialogView"
+	JobsView               = "JobsView"
 	HelpDialog             = "HelpDialog"
+	TrashDialog            = "TrashDialog"
+	ImportDialog           = "ImportDialog"
+	ZoomView               = "ZoomView"
 	SearchListDialog1View  = "SearchListDialog1"
 	SearchListDialog2View  = "SearchListDialog2"
 	SearchListDialog3View  = "SearchListDialog3"
@@ -65,10 +71,18 @@ func getViewPosition(viewName string) *tui.ViewPosition {
 		0,
 	)
 
+	// pinned workspaces
+	positionMap[PinnedView] = tui.NewViewPosition(
+		PinnedView,
+		0, 3,
+		maxX/3-1, 5,
+		0,
+	)
+
 	// topics
 	positionMap[TopicView] = tui.NewViewPosition(
 		TopicView,
-		0, 3,
+		0, 6,
 		maxX/3-1, maxY/3-1,
 		0,
 	)
@@ -93,14 +107,14 @@ func getViewPosition(viewName string) *tui.ViewPosition {
 	positionMap[WorkspaceInfoView] = tui.NewViewPosition(
 		WorkspaceInfoView,
 		maxX/3, 0,
-		maxX-1, 6,
+		maxX-1, 7,
 		0,
 	)
 
 	// preview
 	positionMap[PreviewView] = tui.NewViewPosition(
 		PreviewView,
-		maxX/3, 7,
+		maxX/3, 8,
 		maxX-1, maxY-1,
 		0,
 	)
@@ -1,11 +1,12 @@
 package app
 
 import (
+	"context"
 	"fmt"
-	"net/url"
-	"path"
+	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
+	"sync/atomic"
 
 	"github.com/GianlucaP106/mynav/pkg/core"
 	"github.com/GianlucaP106/mynav/pkg/tui"
@@ -23,6 +24,9 @@ type Workspaces struct {
 
 	// loading flag to display loading
 	loading bool
+
+	// if git statuses are being loaded in the background
+	gitLoading atomic.Bool
 }
 
 func newWorkspcacesView() *Workspaces {
@@ -70,6 +74,7 @@ func (wv *Workspaces) showInfo() {
 
 func (wv *Workspaces) refreshPreview() {
 	w := wv.selected()
+	a.preview.setWorkspace(w)
 	if w == nil {
 		a.preview.setSession(nil)
 		return
@@ -97,26 +102,77 @@ func (wv *Workspaces) refresh() {
 		workspaces = make(core.Workspaces, 0)
 	}
 
+	showGit := a.api.ShowGitColumns()
 	sMap := a.api.SessionMap()
 	tableRows := make([]*tui.TableRow[*core.Workspace], 0)
-	for _, w := range workspaces.Sorted() {
+	for _, w := range a.api.SortWorkspaces(workspaces, a.api.SortMode(WorkspacesView)) {
 		tmux := ""
 		s := sMap.Get(w)
 		if s != nil {
 			tmux = "Yes"
 		}
 		timeStr := core.TimeAgo(w.LastModified())
+		name := w.Name
+		if w.Linked() {
+			name += " (linked)"
+		}
+		cols := []string{
+			name,
+			tmux,
+			strings.Join(a.api.WorkspaceTags(w), ","),
+		}
+		if showGit {
+			branch, status := gitStatusCols(w)
+			cols = append(cols, branch, status)
+		}
+		cols = append(cols, timeStr)
 		tableRows = append(tableRows, &tui.TableRow[*core.Workspace]{
-			Cols: []string{
-				w.Name,
-				tmux,
-				timeStr,
-			},
+			Cols:  cols,
 			Value: w,
 		})
 	}
 
 	wv.table.Fill(tableRows)
+
+	if showGit {
+		wv.loadGitStatus(workspaces)
+	}
+}
+
+// Loads the git status of the workspaces in the background and refreshes the view once loaded.
+func (wv *Workspaces) loadGitStatus(workspaces core.Workspaces) {
+	if !wv.gitLoading.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		loaded := a.api.LoadGitStatuses(workspaces)
+		wv.gitLoading.Store(false)
+		if !loaded {
+			return
+		}
+
+		a.worker.Queue(func() {
+			wv.refresh()
+			a.ui.Update(func() {
+				wv.render()
+			})
+		})
+	}()
+}
+
+// Returns the branch and status columns of the workspace from the git status cache.
+func gitStatusCols(w *core.Workspace) (string, string) {
+	status, cached := a.api.CachedGitStatus(w)
+	if !cached {
+		return "...", "..."
+	}
+
+	if status == nil {
+		return "", ""
+	}
+
+	return status.Branch, status.String()
 }
 
 func (wv *Workspaces) render() {
@@ -133,7 +189,7 @@ func (wv *Workspaces) render() {
 	// update page row marker
 	row, _ := wv.table.SelectedRow()
 	size := wv.table.Size()
-	wv.view.Subtitle = fmt.Sprintf(" %d / %d ", min(row+1, size), size)
+	wv.view.Subtitle = fmt.Sprintf(" %s | %d / %d ", sortLabel(WorkspacesView), min(row+1, size), size)
 
 	if wv.getLoading() {
 		fmt.Fprintln(wv.view, "Loading...")
@@ -149,29 +205,65 @@ func (wv *Workspaces) render() {
 	})
 }
 
-func (wv *Workspaces) init() {
-	wv.view = a.ui.SetView(getViewPosition(WorkspacesView))
-	a.styleView(wv.view)
-
+// Inits the table columns, depending on if the git columns are shown.
+func (wv *Workspaces) initTable() {
 	sizeX, sizeY := wv.view.Size()
 	titles := []string{
 		"Name",
 		"Session",
+		"Tags",
 		"Last Modified",
 	}
 	proportions := []float64{
-		0.40,
-		0.20,
-		0.40,
+		0.30,
+		0.15,
+		0.25,
+		0.30,
 	}
 	styles := []color.Style{
 		workspaceNameColor,
 		sessionMarkerColor,
+		tagsColor,
 		timestampColor,
 	}
-	wv.table = tui.NewTableRenderer[*core.Workspace]()
+
+	if a.api.ShowGitColumns() {
+		titles = []string{
+			"Name",
+			"Session",
+			"Tags",
+			"Branch",
+			"Git",
+			"Last Modified",
+		}
+		proportions = []float64{
+			0.20,
+			0.10,
+			0.16,
+			0.16,
+			0.14,
+			0.24,
+		}
+		styles = []color.Style{
+			workspaceNameColor,
+			sessionMarkerColor,
+			tagsColor,
+			topicNameColor,
+			alternateSessionMarkerColor,
+			timestampColor,
+		}
+	}
+
 	wv.table.Init(sizeX, sizeY, titles, proportions)
 	wv.table.SetStyles(styles)
+}
+
+func (wv *Workspaces) init() {
+	wv.view = a.ui.SetView(getViewPosition(WorkspacesView))
+	a.styleView(wv.view)
+
+	wv.table = tui.NewTableRenderer[*core.Workspace]()
+	wv.initTable()
 
 	down := func() {
 		wv.table.Down()
@@ -228,14 +320,51 @@ func (wv *Workspaces) init() {
 				return
 			}
 
-			editor(func(s string) {
-				if err := a.api.CloneWorkspaceRepo(curWorkspace, s); err != nil {
+			if a.jobs.running(curWorkspace) {
+				toast("A clone is already running in workspace "+curWorkspace.Name, toastWarn)
+				return
+			}
+
+			form(func(values []string) {
+				uri := values[0]
+				if uri == "" {
+					toast("Git url must not be empty", toastError)
+					return
+				}
+
+				opts, err := cloneOptions(values[1], values[2], values[3])
+				if err != nil {
 					toast(err.Error(), toastError)
+					return
 				}
 
-				a.refreshAll()
-				toast("Cloned repo to workspace "+curWorkspace.Name, toastInfo)
-			}, func() {}, "Git repo URL", smallEditorSize, "")
+				a.jobs.start("Cloning "+curWorkspace.Name, curWorkspace.Path(), func(ctx context.Context, job *Job) error {
+					return a.api.CloneWorkspaceRepo(ctx, curWorkspace, uri, opts, job.setProgress)
+				}, func(err error) {
+					a.refreshAll()
+					if err != nil {
+						toast(err.Error(), toastError)
+						return
+					}
+
+					toast("Cloned repo to workspace "+curWorkspace.Name, toastInfo)
+				})
+			}, func() {}, "Clone git repo",
+				textField("Git url", ""),
+				textField("Branch (default if empty)", ""),
+				textField("Depth (full history if empty)", ""),
+				choiceField("Submodules", "no", "yes"),
+			)
+		}).
+		Set('x', "Cancel clone", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			if !a.jobs.cancel(curWorkspace) {
+				toast("No clone is running in workspace "+curWorkspace.Name, toastWarn)
+			}
 		}).
 		Set('I', "Open browser to git repo", func() {
 			curWorkspace := wv.selected()
@@ -272,23 +401,9 @@ func (wv *Workspaces) init() {
 				return
 			}
 
-			if core.IsTmuxSession() {
-				toast("A tmux session is already active", toastWarn)
-				return
-			}
-
-			start := time.Now()
-			err := a.runAction(func() error {
+			a.openSession(curWorkspace.Name, func() error {
 				return a.api.OpenWorkspace(curWorkspace)
 			})
-			if err != nil {
-				toast(err.Error(), toastError)
-			} else {
-				timeTaken := time.Since(start)
-				s := fmt.Sprintf("Detached session %s - %s active", curWorkspace.Name, core.TimeDeltaStr(timeTaken))
-				toast(s, toastInfo)
-			}
-
 			a.refresh(curWorkspace.Topic, curWorkspace, nil)
 		}).
 		Set('m', "Move workspace", func() {
@@ -297,42 +412,34 @@ func (wv *Workspaces) init() {
 				return
 			}
 
-			sd := new(*Search[*core.Topic])
-			*sd = search(SearchDialogConfig[*core.Topic]{
-				onSearch: func(s string) []*tui.TableRow[*core.Topic] {
-					rows := make([]*tui.TableRow[*core.Topic], 0)
-					topics := a.api.Topics()
-					filtered := core.Topics{}
-					for _, topic := range topics {
-						if strings.Contains(topic.Name, s) {
-							filtered = append(filtered, topic)
-						}
-					}
+			topicRows := func(s string) []*tui.TableRow[*core.Topic] {
+				topics := a.api.Topics()
+				names := make([]string, len(topics))
+				for i, t := range topics {
+					names[i] = t.Name
+				}
 
-					for _, t := range filtered {
-						rows = append(rows, &tui.TableRow[*core.Topic]{
-							Cols: []string{
-								t.Name,
-							},
-							Value: t,
-						})
-					}
+				rows := make([]*tui.TableRow[*core.Topic], 0)
+				for _, match := range core.FuzzyFilter(names, s) {
+					t := topics[match.Index]
+					rows = append(rows, &tui.TableRow[*core.Topic]{
+						Cols: []string{
+							t.Name,
+						},
+						Value:   t,
+						Matches: [][]int{match.Positions},
+					})
+				}
 
-					return rows
-				},
-				initial: func() []*tui.TableRow[*core.Topic] {
-					rows := make([]*tui.TableRow[*core.Topic], 0)
-					topics := a.api.Topics()
-					for _, t := range topics {
-						rows = append(rows, &tui.TableRow[*core.Topic]{
-							Cols: []string{
-								t.Name,
-							},
-							Value: t,
-						})
-					}
+				return rows
+			}
 
-					return rows
+			sd := new(*Search[*core.Topic])
+			*sd = search(SearchDialogConfig[*core.Topic]{
+				onSearch: topicRows,
+				onType:   topicRows,
+				initial: func() []*tui.TableRow[*core.Topic] {
+					return topicRows("")
 				},
 				onSelect: func(t *core.Topic) {
 					if err := a.api.MoveWorkspace(curWorkspace, t); err != nil {
@@ -366,14 +473,22 @@ func (wv *Workspaces) init() {
 				return
 			}
 
-			alert(func(b bool) {
-				if b {
-					t := curWorkspace.Topic
-					a.api.DeleteWorkspace(curWorkspace)
-					a.refresh(t, nil, nil)
-					toast("Deleted workspace "+curWorkspace.Name, toastInfo)
+			msg := fmt.Sprintf("Are you sure you want to delete workspace %s? It is moved to the trash and kept %s.", curWorkspace.Name, trashRetention())
+			if curWorkspace.Linked() {
+				msg = fmt.Sprintf("Are you sure you want to delete workspace %s? Only the link is moved to the trash, %s is kept.", curWorkspace.Name, core.HomeShortPath(curWorkspace.RealPath()))
+			}
+			confirmDelete(func() {
+				t := curWorkspace.Topic
+				if err := a.api.DeleteWorkspace(curWorkspace); err != nil {
+					toast(err.Error(), toastError)
+					return
 				}
-			}, fmt.Sprintf("Are you sure you want to delete workspace %s?", curWorkspace.Name))
+
+				a.refresh(t, nil, nil)
+				toast("Deleted workspace "+curWorkspace.Name, toastInfo)
+			}, curWorkspace.Name, msg, func() ([]*core.GitRisk, error) {
+				return a.api.WorkspaceRisks(curWorkspace)
+			})
 		}).
 		Set('r', "Rename workspace", func() {
 			curWorkspace := wv.selected()
@@ -391,6 +506,99 @@ func (wv *Workspaces) init() {
 				toast("Renamed workspace "+curWorkspace.Name, toastInfo)
 			}, func() {}, "New workspace name", smallEditorSize, curWorkspace.Name)
 		}).
+		Set('t', "Edit tags", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			editor(func(s string) {
+				if err := a.api.SetWorkspaceTags(curWorkspace, core.ParseTags(s)); err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				a.refresh(curWorkspace.Topic, curWorkspace, nil)
+				toast("Updated tags of "+curWorkspace.Name, toastInfo)
+			}, func() {}, "Tags (separated by spaces)", smallEditorSize, strings.Join(a.api.WorkspaceTags(curWorkspace), " "))
+		}).
+		Set('d', "Edit description", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			if err := a.runAction(func() error {
+				return a.api.EditWorkspaceDescription(curWorkspace)
+			}); err != nil {
+				toast(err.Error(), toastError)
+			}
+			a.refresh(curWorkspace.Topic, curWorkspace, nil)
+		}).
+		Set('e', "Edit notes", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			if err := a.runAction(func() error {
+				return a.api.EditWorkspaceNotes(curWorkspace)
+			}); err != nil {
+				toast(err.Error(), toastError)
+			}
+			a.refresh(curWorkspace.Topic, curWorkspace, nil)
+		}).
+		Set('p', "Pin workspace", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			slot := a.api.PinSlot(curWorkspace)
+			if slot == 0 {
+				slot = a.api.FreePinSlot()
+			}
+			initial := ""
+			if slot != 0 {
+				initial = strconv.Itoa(slot)
+			}
+
+			editor(func(s string) {
+				slot, err := core.ParsePinSlot(s)
+				if err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				if err := a.api.Pin(curWorkspace, slot); err != nil {
+					toast(err.Error(), toastError)
+					return
+				}
+
+				a.refresh(curWorkspace.Topic, curWorkspace, nil)
+				toast(fmt.Sprintf("Pinned %s to slot %d", curWorkspace.Name, slot), toastInfo)
+			}, func() {}, fmt.Sprintf("Pin to slot (1-%d)", core.PinSlots), smallEditorSize, initial)
+		}).
+		Set('P', "Unpin workspace", func() {
+			curWorkspace := wv.selected()
+			if curWorkspace == nil {
+				return
+			}
+
+			slot := a.api.PinSlot(curWorkspace)
+			if slot == 0 {
+				toast(curWorkspace.Name+" is not pinned", toastWarn)
+				return
+			}
+
+			if err := a.api.Unpin(slot); err != nil {
+				toast(err.Error(), toastError)
+				return
+			}
+
+			a.refresh(curWorkspace.Topic, curWorkspace, nil)
+			toast(fmt.Sprintf("Unpinned %s from slot %d", curWorkspace.Name, slot), toastInfo)
+		}).
 		Set('A', "Create a workspace from git url", func() {
 			curTopic := a.topics.selected()
 			if curTopic == nil {
@@ -398,47 +606,113 @@ func (wv *Workspaces) init() {
 				return
 			}
 
-			editor(func(uri string) {
-				_, err := url.ParseRequestURI(uri)
-				if err != nil {
-					toast("URL must be valid", toastError)
+			form(func(values []string) {
+				uri := values[0]
+				if uri == "" {
+					toast("Git url must not be empty", toastError)
 					return
 				}
 
-				urlTrimmed := strings.TrimSuffix(uri, ".git")
-				name := path.Base(urlTrimmed)
-				w, err := a.api.NewWorkspace(curTopic, name)
+				opts, err := cloneOptions(values[1], values[2], values[3])
 				if err != nil {
 					toast(err.Error(), toastError)
 					return
 				}
 
-				if err := a.api.CloneWorkspaceRepo(w, uri); err != nil {
+				name := values[5]
+				if values[4] != customNaming {
+					name, err = core.RepoName(uri, values[4])
+					if err != nil {
+						toast(err.Error(), toastError)
+						return
+					}
+				}
+
+				// report collisions before starting the clone
+				if err := a.api.ValidateWorkspaceName(curTopic, name); err != nil {
 					toast(err.Error(), toastError)
 					return
 				}
 
-				a.refresh(curTopic, w, nil)
-				toast("Cloned and created workspace "+w.Name, toastInfo)
-			}, func() {}, "Git url", smallEditorSize, "")
+				a.jobs.start("Cloning "+name, filepath.Join(curTopic.Path(), name), func(ctx context.Context, job *Job) error {
+					w, err := a.api.CloneWorkspace(ctx, curTopic, name, uri, opts, job.setProgress)
+					if err != nil {
+						return err
+					}
+
+					a.refresh(curTopic, w, nil)
+					return nil
+				}, func(err error) {
+					if err != nil {
+						a.refreshAll()
+						toast(err.Error(), toastError)
+						return
+					}
+
+					toast("Cloned and created workspace "+name, toastInfo)
+				})
+			}, func() {}, "Create a workspace from git url",
+				textField("Git url", ""),
+				textField("Branch (default if empty)", ""),
+				textField("Depth (full history if empty)", ""),
+				choiceField("Submodules", "no", "yes"),
+				choiceField("Naming", core.RepoNaming, core.OwnerRepoNaming, customNaming),
+				textField("Custom name", ""),
+			)
 		}).
-		Set('a', "Create a workspace", func() {
+		Set('L', "Link an external directory as a workspace", func() {
 			curTopic := a.topics.selected()
 			if curTopic == nil {
 				toast("You must create a topic first", toastWarn)
 				return
 			}
 
-			editor(func(name string) {
-				w, err := a.api.NewWorkspace(curTopic, name)
+			form(func(values []string) {
+				w, err := a.api.LinkWorkspace(curTopic, core.ExpandHome(values[0]), values[1])
 				if err != nil {
 					toast(err.Error(), toastError)
 					return
 				}
 
 				a.refresh(curTopic, w, nil)
-				toast("Created workspace "+w.Name, toastInfo)
-			}, func() {}, "Name", smallEditorSize, "")
+				toast("Linked workspace "+w.Name+" to "+core.HomeShortPath(w.RealPath()), toastInfo)
+			}, func() {}, "Link an external directory",
+				textField("Directory", ""),
+				textField("Name (directory name if empty)", ""),
+			)
+		}).
+		Set('a', "Create a workspace", func() {
+			curTopic := a.topics.selected()
+			if curTopic == nil {
+				toast("You must create a topic first", toastWarn)
+				return
+			}
+
+			templates := a.api.Templates(curTopic)
+			if len(templates) == 0 {
+				editor(func(name string) {
+					w, err := a.api.NewWorkspace(curTopic, name)
+					if err != nil {
+						toast(err.Error(), toastError)
+						return
+					}
+
+					a.refresh(curTopic, w, nil)
+					toast("Created workspace "+w.Name, toastInfo)
+				}, func() {}, "Name", smallEditorSize, "")
+				return
+			}
+
+			options := []string{noTemplate}
+			for _, tmpl := range templates {
+				options = append(options, tmpl.Name)
+			}
+			form(func(values []string) {
+				wv.newFromTemplate(curTopic, values[0], values[1])
+			}, func() {}, "Create a workspace",
+				textField("Name", ""),
+				choiceField("Template", options...),
+			)
 		}).
 		Set('X', "Kill session", func() {
 			curWorkspace := wv.selected()
@@ -452,7 +726,7 @@ func (wv *Workspaces) init() {
 						return
 					}
 
-					if err := s.Kill(); err != nil {
+					if err := a.api.KillSession(s); err != nil {
 						toast(err.Error(), toastError)
 						return
 					}
@@ -462,6 +736,33 @@ func (wv *Workspaces) init() {
 				}, fmt.Sprintf("Are you sure you want to kill session for %s?", curWorkspace.Name))
 			}
 		}).
+		Set('v', "Toggle git columns", func() {
+			selected := wv.selected()
+			a.api.SetShowGitColumns(!a.api.ShowGitColumns())
+			wv.initTable()
+			a.worker.Queue(func() {
+				wv.refresh()
+				if selected != nil {
+					wv.selectWorkspace(selected)
+				}
+				a.ui.Update(func() {
+					wv.render()
+				})
+			})
+		}).
+		Set('o', "Cycle sort order", func() {
+			selected := wv.selected()
+			a.cycleSort(WorkspacesView)
+			a.worker.Queue(func() {
+				wv.refresh()
+				if selected != nil {
+					wv.selectWorkspace(selected)
+				}
+				a.ui.Update(func() {
+					wv.render()
+				})
+			})
+		}).
 		Set('h', "Focus topics view", func() {
 			a.topics.focus()
 		}).
@@ -486,3 +787,60 @@ func (wv *Workspaces) init() {
 			help(wv.view)
 		})
 }
+
+// Creates a workspace in the topic from the template picked in the create dialog, noTemplate creates an empty workspace.
+// The template runs in the background as its commands may take a while.
+func (wv *Workspaces) newFromTemplate(t *core.Topic, name string, templateName string) {
+	if templateName == noTemplate {
+		w, err := a.api.NewWorkspace(t, name)
+		if err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		a.refresh(t, w, nil)
+		toast("Created workspace "+w.Name, toastInfo)
+		return
+	}
+
+	tmpl, err := a.api.Template(t, templateName)
+	if err != nil {
+		toast(err.Error(), toastError)
+		return
+	}
+
+	toast("Creating "+name+" from "+tmpl.Name, toastInfo)
+	go func() {
+		w, err := a.api.NewWorkspaceFromTemplate(t, name, tmpl)
+		if err == nil {
+			a.refresh(t, w, nil)
+		}
+		a.ui.Update(func() {
+			if err != nil {
+				toast(err.Error(), toastError)
+				return
+			}
+			toast("Created workspace "+w.Name+" from "+tmpl.Name, toastInfo)
+		})
+	}()
+}
+
+// Option of the create dialog for an empty workspace, it cannot be the name of a template directory.
+const noTemplate = "n/a"
+
+// Naming strategy of the clone dialog where the workspace name is entered.
+const customNaming = "custom"
+
+// Parses the clone options entered in the clone dialogs.
+func cloneOptions(branch string, depth string, submodules string) (*core.CloneOptions, error) {
+	d, err := parseCount(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.CloneOptions{
+		Branch:     branch,
+		Depth:      d,
+		Submodules: submodules == "yes",
+	}, nil
+}
@@ -0,0 +1,450 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+	"github.com/GianlucaP106/mynav/pkg/core"
+	"github.com/GianlucaP106/mynav/pkg/tui"
+	"github.com/awesome-gocui/gocui"
+)
+
+// Colors of the zoom view highlights, from the 256 color palette.
+const (
+	zoomMatchFg        = 16
+	zoomMatchBg        = 220
+	zoomCurrentMatchBg = 208
+	zoomSelectionFg    = 255
+	zoomSelectionBg    = 24
+)
+
+// Zoom shows the history of a pane full screen.
+// It can be scrolled with a cursor, searched and a region can be selected and copied to the clipboard.
+type Zoom struct {
+	view *tui.View
+
+	// title of the view (session and pane)
+	title string
+
+	// captured history of the pane and its text, one entry per line
+	lines []tui.TerminalLine
+	text  []string
+
+	// cursor (cell and line)
+	cx, cy int
+
+	// first visible line
+	oy int
+
+	// selection from the anchor (sx, sy) to the cursor
+	selecting  bool
+	lineSelect bool
+	sx, sy     int
+
+	// last search and its matches
+	query   string
+	matches []zoomMatch
+	match   int
+
+	// view focused before the zoom was opened
+	prevView *tui.View
+}
+
+// zoomMatch is an occurrence of the search query.
+type zoomMatch struct {
+	y, start, end int
+}
+
+// Opens the zoom view on the pane of the session.
+func zoom(session *core.Session, pane *gotmux.Pane, idx int, count int) {
+	capture, err := a.api.CapturePaneHistory(pane)
+	if err != nil {
+		toast(err.Error(), toastError)
+		return
+	}
+
+	z := &Zoom{}
+	z.prevView = a.ui.FocusedView()
+	z.title = fmt.Sprintf(" %s - pane %d / %d ", session.DisplayName(), idx+1, count)
+	z.lines = tui.ParseTerminal(capture.Content)
+
+	// blank lines below the content and the cursor are dropped
+	last := max(capture.CursorY, 0)
+	for i, line := range z.lines {
+		if strings.TrimSpace(line.String()) != "" {
+			last = max(last, i)
+		}
+	}
+	if last < len(z.lines) {
+		z.lines = z.lines[:last+1]
+	}
+
+	z.text = make([]string, len(z.lines))
+	for i, line := range z.lines {
+		z.text[i] = line.String()
+	}
+
+	z.cy = last
+	if capture.CursorY >= 0 && capture.CursorY < len(z.lines) {
+		z.cy = capture.CursorY
+		z.cx = z.lines[z.cy].CellAt(capture.CursorX)
+	}
+
+	maxX, maxY := a.ui.Size()
+	z.view = a.ui.SetView(tui.NewViewPosition(ZoomView, 0, 0, maxX-1, maxY-1, 0))
+	a.styleView(z.view)
+	z.view.TitleColor = onTitleColor
+	z.view.FrameColor = onFrameColor
+	z.view.Title = z.title
+
+	page := func() int {
+		_, h := z.view.Size()
+		return max(h, 1)
+	}
+	a.ui.KeyBinding(z.view).
+		Set('j', "Move down", func() {
+			z.move(0, 1)
+		}).
+		Set('k', "Move up", func() {
+			z.move(0, -1)
+		}).
+		Set('h', "Move left", func() {
+			z.move(-1, 0)
+		}).
+		Set('l', "Move right", func() {
+			z.move(1, 0)
+		}).
+		Set(gocui.KeyArrowDown, "Move down", func() {
+			z.move(0, 1)
+		}).
+		Set(gocui.KeyArrowUp, "Move up", func() {
+			z.move(0, -1)
+		}).
+		Set(gocui.KeyArrowLeft, "Move left", func() {
+			z.move(-1, 0)
+		}).
+		Set(gocui.KeyArrowRight, "Move right", func() {
+			z.move(1, 0)
+		}).
+		Set(gocui.KeyCtrlD, "Scroll half a page down", func() {
+			z.move(0, page()/2)
+		}).
+		Set(gocui.KeyCtrlU, "Scroll half a page up", func() {
+			z.move(0, -page()/2)
+		}).
+		Set(gocui.KeyCtrlF, "Scroll a page down", func() {
+			z.move(0, page())
+		}).
+		Set(gocui.KeyCtrlB, "Scroll a page up", func() {
+			z.move(0, -page())
+		}).
+		Set('g', "Go to top", func() {
+			z.moveTo(0, 0)
+		}).
+		Set('G', "Go to bottom", func() {
+			z.moveTo(0, len(z.lines)-1)
+		}).
+		Set('0', "Go to start of line", func() {
+			z.moveTo(0, z.cy)
+		}).
+		Set('$', "Go to end of line", func() {
+			z.moveTo(len(z.currentLine())-1, z.cy)
+		}).
+		Set('/', "Search", func() {
+			editor(func(s string) {
+				z.search(s)
+			}, func() {}, "Search", smallEditorSize, "")
+		}).
+		Set('n', "Next match", func() {
+			z.next(true)
+		}).
+		Set('N', "Previous match", func() {
+			z.next(false)
+		}).
+		Set('v', "Select characters", func() {
+			z.startSelection(false)
+		}).
+		Set('V', "Select lines", func() {
+			z.startSelection(true)
+		}).
+		Set('y', "Copy selection", z.copy).
+		Set(gocui.KeyEnter, "Copy selection", z.copy).
+		Set(gocui.KeyEsc, "Clear selection, search or close", func() {
+			switch {
+			case z.selecting:
+				z.selecting = false
+			case z.query != "":
+				z.query = ""
+				z.matches = nil
+			default:
+				z.close()
+				return
+			}
+			z.render()
+		}).
+		Set('q', "Close zoom", z.close).
+		Set('z', "Close zoom", z.close).
+		Set('?', "Toggle cheatsheet", func() {
+			help(z.view)
+		})
+
+	a.ui.FocusView(z.view)
+	z.render()
+}
+
+func (z *Zoom) currentLine() tui.TerminalLine {
+	if z.cy >= len(z.lines) {
+		return nil
+	}
+
+	return z.lines[z.cy]
+}
+
+// Moves the cursor by a number of cells and lines.
+func (z *Zoom) move(dx int, dy int) {
+	z.moveTo(z.cx+dx, z.cy+dy)
+}
+
+// Moves the cursor to the cell of the line, keeping it within the content.
+func (z *Zoom) moveTo(x int, y int) {
+	z.cy = max(min(y, len(z.lines)-1), 0)
+	z.cx = max(min(x, len(z.currentLine())-1), 0)
+	z.render()
+}
+
+func (z *Zoom) startSelection(lines bool) {
+	if z.selecting && z.lineSelect == lines {
+		z.selecting = false
+	} else {
+		if !z.selecting {
+			z.sx, z.sy = z.cx, z.cy
+		}
+		z.selecting = true
+		z.lineSelect = lines
+	}
+
+	z.render()
+}
+
+// Returns the selection, ordered from its start to its end.
+func (z *Zoom) selection() (x0 int, y0 int, x1 int, y1 int) {
+	x0, y0, x1, y1 = z.sx, z.sy, z.cx, z.cy
+	if y0 > y1 || y0 == y1 && x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+
+	if z.lineSelect {
+		x0 = 0
+		x1 = max(len(z.lines[y1])-1, 0)
+	}
+
+	return x0, y0, x1, y1
+}
+
+// Copies the selection (or the line under the cursor) to the clipboard.
+func (z *Zoom) copy() {
+	if len(z.lines) == 0 {
+		return
+	}
+
+	x0, y0, x1, y1 := z.cx, z.cy, z.cx, z.cy
+	if z.selecting {
+		x0, y0, x1, y1 = z.selection()
+	} else {
+		x0, x1 = 0, len(z.lines[y0])
+	}
+
+	out := make([]string, 0)
+	for y := y0; y <= y1; y++ {
+		runes := []rune(z.text[y])
+		start, end := 0, len(runes)
+		if y == y0 {
+			start = min(x0, end)
+		}
+		if y == y1 {
+			end = min(x1+1, end)
+		}
+		out = append(out, strings.TrimRightFunc(string(runes[start:end]), unicode.IsSpace))
+	}
+	s := strings.Join(out, "\n")
+
+	z.selecting = false
+	z.render()
+
+	// the clipboard is not available without a clipboard utility (e.g. over ssh), tmux can forward it instead
+	if err := core.CopyToClip(s); err != nil {
+		if err := a.api.CopyToTmuxBuffer(s); err != nil {
+			toast(err.Error(), toastError)
+			return
+		}
+
+		toast(fmt.Sprintf("Copied %d lines to the tmux buffer", len(out)), toastInfo)
+		return
+	}
+
+	toast(fmt.Sprintf("Copied %d lines to the clipboard", len(out)), toastInfo)
+}
+
+// Finds the occurrences of the query and moves to the first one after the cursor.
+// The search is case insensitive unless the query contains an uppercase letter.
+func (z *Zoom) search(query string) {
+	z.query = query
+	z.matches = nil
+	if query == "" {
+		z.render()
+		return
+	}
+
+	fold := strings.ToLower(query) == query
+	size := utf8.RuneCountInString(query)
+	for y, text := range z.text {
+		if fold {
+			text = strings.ToLower(text)
+		}
+
+		// cells hold one rune each, so matches are found in bytes and converted to cells
+		offset := 0
+		for {
+			i := strings.Index(text[offset:], query)
+			if i < 0 {
+				break
+			}
+
+			start := utf8.RuneCountInString(text[:offset+i])
+			z.matches = append(z.matches, zoomMatch{y: y, start: start, end: start + size})
+			offset += i + len(query)
+		}
+	}
+
+	if len(z.matches) == 0 {
+		z.render()
+		toast("Pattern not found: "+query, toastWarn)
+		return
+	}
+
+	// start before the cursor so that a match under it comes first
+	z.cx--
+	z.next(true)
+}
+
+// Moves the cursor to the next (or previous) match from the cursor, wrapping around.
+func (z *Zoom) next(forward bool) {
+	if len(z.matches) == 0 {
+		return
+	}
+
+	z.match = -1
+	if forward {
+		for i, m := range z.matches {
+			if m.y > z.cy || m.y == z.cy && m.start > z.cx {
+				z.match = i
+				break
+			}
+		}
+		if z.match == -1 {
+			z.match = 0
+		}
+	} else {
+		for i := len(z.matches) - 1; i >= 0; i-- {
+			m := z.matches[i]
+			if m.y < z.cy || m.y == z.cy && m.start < z.cx {
+				z.match = i
+				break
+			}
+		}
+		if z.match == -1 {
+			z.match = len(z.matches) - 1
+		}
+	}
+
+	m := z.matches[z.match]
+	z.moveTo(m.start, m.y)
+}
+
+func (z *Zoom) render() {
+	maxX, maxY := a.ui.Size()
+	a.ui.Resize(z.view, tui.NewViewPosition(ZoomView, 0, 0, maxX-1, maxY-1, 0))
+	w, h := z.view.Size()
+
+	// keep the cursor in view
+	if z.cy < z.oy {
+		z.oy = z.cy
+	}
+	if z.cy >= z.oy+h {
+		z.oy = z.cy - h + 1
+	}
+
+	ox := 0
+	if line := z.currentLine(); z.cx < len(line) {
+		col := 0
+		for _, c := range line[:z.cx+1] {
+			col += c.Width
+		}
+		ox = max(col-w, 0)
+	}
+
+	z.view.Clear()
+	for y := z.oy; y < z.oy+h && y < len(z.lines); y++ {
+		line := z.lines[y]
+		highlights := make([]tui.Highlight, 0)
+		for i, m := range z.matches {
+			if m.y != y {
+				continue
+			}
+
+			bg := zoomMatchBg
+			if i == z.match {
+				bg = zoomCurrentMatchBg
+			}
+			highlights = append(highlights, tui.Highlight{Start: m.start, End: m.end, Fg: zoomMatchFg, Bg: bg})
+		}
+
+		if z.selecting {
+			x0, y0, x1, y1 := z.selection()
+			if y >= y0 && y <= y1 {
+				start, end := 0, len(line)
+				if y == y0 {
+					start = x0
+				}
+				if y == y1 {
+					end = x1 + 1
+				}
+				line = line.Pad(end)
+				highlights = append(highlights, tui.Highlight{Start: start, End: end, Fg: zoomSelectionFg, Bg: zoomSelectionBg})
+			}
+		}
+
+		if y == z.cy {
+			line = line.Pad(z.cx + 1)
+			highlights = append(highlights, tui.Highlight{Start: z.cx, End: z.cx + 1, Reverse: true})
+		}
+
+		fmt.Fprintln(z.view, line.Render(highlights...))
+	}
+
+	// the origin of the view is in cells
+	z.view.SetOrigin(z.currentLine().CellAt(ox), 0)
+
+	subtitle := fmt.Sprintf(" %d / %d ", z.cy+1, len(z.lines))
+	if len(z.matches) > 0 {
+		subtitle = fmt.Sprintf(" /%s %d / %d |%s", z.query, z.match+1, len(z.matches), subtitle)
+	}
+	if z.selecting {
+		mode := "VISUAL"
+		if z.lineSelect {
+			mode = "VISUAL LINE"
+		}
+		subtitle = fmt.Sprintf(" %s |%s", mode, subtitle)
+	}
+	z.view.Subtitle = subtitle
+}
+
+func (z *Zoom) close() {
+	a.ui.DeleteView(z.view)
+	if z.prevView != nil {
+		a.ui.FocusView(z.prevView)
+	}
+}
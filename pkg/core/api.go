@@ -1,19 +1,28 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/GianlucaP106/gotmux/gotmux"
 )
 
 // API exposes all core api functions.
 type API struct {
-	fs      *Filesystem
-	tmux    *gotmux.Tmux
-	local   *LocalConfig
-	global  *GlobalConfig
-	updater *updater
+	fs        *Filesystem
+	tmux      *gotmux.Tmux
+	local     *LocalConfig
+	global    *GlobalConfig
+	snapshots *Snapshots
+	trash     *Trash
+	undo      *undoStack
+	control   *ControlClient
+	gitStatus *GitStatusCache
+	updater   *updater
 }
 
 // Inits the Api.
@@ -40,6 +49,16 @@ func NewApi(dir string) (*API, error) {
 		return nil, err
 	}
 
+	snapshots, err := newSnapshots(local.path)
+	if err != nil {
+		return nil, err
+	}
+
+	trash, err := newTrash(local.path)
+	if err != nil {
+		return nil, err
+	}
+
 	c := newFilesystem(local.path)
 
 	api := &API{}
@@ -47,7 +66,17 @@ func NewApi(dir string) (*API, error) {
 	api.tmux = tmux
 	api.local = local
 	api.global = global
+	api.snapshots = snapshots
+	api.trash = trash
+	api.undo = newUndoStack()
+	api.gitStatus = newGitStatusCache()
 	api.updater = &updater{}
+
+	// permanently delete the items that have been in the trash for too long
+	if retention := api.TrashRetention(); retention > 0 {
+		api.purgeTrashOlderThan(retention)
+	}
+
 	return api, nil
 }
 
@@ -66,34 +95,88 @@ func (a *API) Topics() Topics {
 	return a.fs.Topics()
 }
 
+// Returns a Topic object if the name is valid.
+func (a *API) Topic(name string) *Topic {
+	return a.fs.Topic(name)
+}
+
 // Returns topic count.
 func (a *API) TopicCount() int {
 	return a.fs.TopicsCount()
 }
 
-// Deletes a topic.
+// Deletes a topic, moving it to the trash.
+// The sessions of its workspaces are killed once it is in the trash so that they are kept if it cannot be moved.
 func (a *API) DeleteTopic(t *Topic) error {
-	for _, w := range a.Workspaces(t) {
+	sessions := make([]*Session, 0)
+	for _, w := range a.TopicWorkspaces(t) {
 		if s := a.Session(w); s != nil {
-			s.Kill()
+			sessions = append(sessions, s)
 		}
 	}
+
+	item, err := a.trash.Put(TrashTopic, t.Path())
+	if err != nil {
+		return err
+	}
 	a.SelectWorkspace(nil)
-	return a.fs.DeleteTopic(t)
+
+	a.undo.push("delete topic "+t.Name, func() error {
+		return a.trash.Restore(item)
+	})
+
+	for _, s := range sessions {
+		if err := a.KillSession(s); err != nil {
+			return fmt.Errorf("topic %s was deleted but its session %s could not be killed: %w", t.Name, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// Returns the git repositories of the topic with uncommitted, untracked, stashed or unpushed work.
+func (a *API) TopicRisks(t *Topic) ([]*GitRisk, error) {
+	return GitRisks(a.fs.path, t.Path())
 }
 
 // Renames a topic.
 func (a *API) RenameTopic(t *Topic, name string) error {
+	oldName := t.Name
+	if err := a.renameTopic(t, name); err != nil {
+		return err
+	}
+
+	a.undo.push("rename topic "+name, func() error {
+		t := a.Topic(name)
+		if t == nil {
+			return fmt.Errorf("topic %s no longer exists", name)
+		}
+
+		return a.renameTopic(t, oldName)
+	})
+	return nil
+}
+
+// Moves the config, the files and the session snapshots of a workspace, or of all the workspaces of a topic, to a new short path.
+func (a *API) moveWorkspaceData(oldPath string, newPath string) {
+	a.local.MoveWorkspaceData(oldPath, newPath)
+	moveWorkspaceFiles(a.fs.path, oldPath, newPath)
+	a.snapshots.Move(oldPath, newPath)
+}
+
+func (a *API) renameTopic(t *Topic, name string) error {
 	// store topic path for session rename
 	oldTopicPath := t.Path()
+	oldName := t.Name
 
 	if err := a.fs.RenameTopic(t, name); err != nil {
 		return err
 	}
+	a.moveWorkspaceData(oldName, t.Name)
 
-	// rename all sessions
-	for _, w := range a.Workspaces(t) {
-		session, err := a.tmux.GetSessionByName(filepath.Join(oldTopicPath, w.Name))
+	// rename all sessions, including the ones of the sub-topics
+	for _, w := range a.TopicWorkspaces(t) {
+		oldPath := filepath.Join(oldTopicPath, strings.TrimPrefix(w.Path(), t.Path()))
+		session, err := a.tmux.GetSessionByName(oldPath)
 		if err != nil {
 			return err
 		}
@@ -118,11 +201,46 @@ func (a *API) NewWorkspace(t *Topic, name string) (*Workspace, error) {
 	return w, nil
 }
 
+// Returns an error if a workspace cannot be created with this name in the topic (e.g. it already exists).
+func (a *API) ValidateWorkspaceName(t *Topic, name string) error {
+	return a.fs.ValidateWorkspaceName(t, name)
+}
+
+// Creates a workspace in the topic linking to the directory at target, which stays where it is.
+// The name of the directory is used if name is empty.
+func (a *API) LinkWorkspace(t *Topic, target string, name string) (*Workspace, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if target == a.fs.path || strings.HasPrefix(target, a.fs.path+"/") {
+		return nil, fmt.Errorf("%s is inside the mynav root, move it instead", target)
+	}
+
+	if name == "" {
+		name = filepath.Base(target)
+	}
+
+	return a.fs.LinkWorkspace(t, target, name)
+}
+
 // Returns the workspaces for this topic.
 func (a *API) Workspaces(t *Topic) Workspaces {
 	return a.fs.Workspaces(t)
 }
 
+// Returns the workspaces of the topic and of its sub-topics at any depth.
+func (a *API) TopicWorkspaces(t *Topic) Workspaces {
+	out := make(Workspaces, 0)
+	for _, w := range a.AllWorkspaces() {
+		if t.Contains(w.Topic.Name) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
 // Returns all workspaces.
 func (a *API) AllWorkspaces() Workspaces {
 	return a.fs.AllWorkspaces()
@@ -133,26 +251,69 @@ func (a *API) WorkspacesCount() int {
 	return a.fs.WorkspacesCount()
 }
 
-// Deletes this workspace.
+// Deletes this workspace, moving it to the trash.
+// Its session is killed once it is in the trash so that it is kept if the workspace cannot be moved.
 func (a *API) DeleteWorkspace(w *Workspace) error {
-	if s := a.Session(w); s != nil {
-		s.Kill()
-	}
+	session := a.Session(w)
 	selected := a.SelectedWorkspace()
+
+	item, err := a.trash.Put(TrashWorkspace, w.Path())
+	if err != nil {
+		return err
+	}
+
 	if selected == w {
 		a.SelectWorkspace(nil)
 	}
 
-	return a.fs.DeleteWorkspace(w)
+	a.undo.push("delete workspace "+w.ShortPath(), func() error {
+		return a.trash.Restore(item)
+	})
+
+	if session != nil {
+		if err := a.KillSession(session); err != nil {
+			return fmt.Errorf("workspace %s was deleted but its session could not be killed: %w", w.ShortPath(), err)
+		}
+	}
+	return nil
+}
+
+// Returns the git repositories of the workspace with uncommitted, untracked, stashed or unpushed work.
+// Nothing is at risk for a linked workspace, deleting it only removes the link.
+func (a *API) WorkspaceRisks(w *Workspace) ([]*GitRisk, error) {
+	if w.Linked() {
+		return nil, nil
+	}
+	return GitRisks(a.fs.path, w.Path())
 }
 
 // Renames the workspace.
 func (a *API) RenameWorkspace(w *Workspace, name string) error {
+	oldName := w.Name
+	if err := a.renameWorkspace(w, name); err != nil {
+		return err
+	}
+
+	shortPath := w.ShortPath()
+	a.undo.push("rename workspace "+shortPath, func() error {
+		w := a.Workspace(shortPath)
+		if w == nil {
+			return fmt.Errorf("workspace %s no longer exists", shortPath)
+		}
+
+		return a.renameWorkspace(w, oldName)
+	})
+	return nil
+}
+
+func (a *API) renameWorkspace(w *Workspace, name string) error {
 	s := a.Session(w)
+	oldShortPath := w.ShortPath()
 
 	if err := a.fs.RenameWorkspace(w, name); err != nil {
 		return err
 	}
+	a.moveWorkspaceData(oldShortPath, w.ShortPath())
 
 	// rename session to new path
 	if s != nil {
@@ -164,10 +325,35 @@ func (a *API) RenameWorkspace(w *Workspace, name string) error {
 
 // Moves the workspace to a different topic.
 func (a *API) MoveWorkspace(w *Workspace, topic *Topic) error {
+	oldTopic := w.Topic.Name
+	if err := a.moveWorkspace(w, topic); err != nil {
+		return err
+	}
+
+	shortPath := w.ShortPath()
+	a.undo.push("move workspace "+shortPath, func() error {
+		w := a.Workspace(shortPath)
+		if w == nil {
+			return fmt.Errorf("workspace %s no longer exists", shortPath)
+		}
+
+		t := a.Topic(oldTopic)
+		if t == nil {
+			return fmt.Errorf("topic %s no longer exists", oldTopic)
+		}
+
+		return a.moveWorkspace(w, t)
+	})
+	return nil
+}
+
+func (a *API) moveWorkspace(w *Workspace, topic *Topic) error {
 	s := a.Session(w)
+	oldShortPath := w.ShortPath()
 	if err := a.fs.MoveWorkspace(w, topic); err != nil {
 		return err
 	}
+	a.moveWorkspaceData(oldShortPath, w.ShortPath())
 
 	// rename session to new path
 	if s != nil {
@@ -198,10 +384,50 @@ func (s *API) Workspace(shortPath string) *Workspace {
 	return s.fs.Workspace(shortPath)
 }
 
-// Clones repo into workspace.
-func (a *API) CloneWorkspaceRepo(w *Workspace, url string) error {
+// Returns the cached git status of the workspace (nil if not a git repository), and if it was cached at all.
+func (a *API) CachedGitStatus(w *Workspace) (*GitStatus, bool) {
+	return a.gitStatus.Get(w)
+}
+
+// Loads the git status of the workspaces that are not cached or expired.
+// Blocks until loaded, returns if any status was loaded.
+func (a *API) LoadGitStatuses(workspaces Workspaces) bool {
+	return a.gitStatus.Load(workspaces)
+}
+
+// Returns if the git columns are shown in the workspaces view.
+func (a *API) ShowGitColumns() bool {
+	return a.local.ConfigData().GitColumns
+}
+
+// Sets if the git columns are shown in the workspaces view.
+func (a *API) SetShowGitColumns(show bool) {
+	a.local.SetGitColumns(show)
+}
+
+// Clones repo into workspace, opts can be nil.
+// Progress reported by git is passed to onProgress if not nil.
+func (a *API) CloneWorkspaceRepo(ctx context.Context, w *Workspace, url string, opts *CloneOptions, onProgress func(string)) error {
 	a.SelectWorkspace(w)
-	return w.CloneRepo(url)
+	return w.CloneRepo(ctx, url, opts, onProgress)
+}
+
+// Creates a new workspace and clones the repo into it, opts can be nil.
+// If the clone fails or is cancelled, the workspace is removed.
+func (a *API) CloneWorkspace(ctx context.Context, t *Topic, name string, url string, opts *CloneOptions, onProgress func(string)) (*Workspace, error) {
+	w, err := a.NewWorkspace(t, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.CloneWorkspaceRepo(ctx, w, url, opts, onProgress); err != nil {
+		// roll back the workspace that was created for the clone
+		a.SelectWorkspace(nil)
+		a.fs.RemoveWorkspace(w)
+		return nil, err
+	}
+
+	return w, nil
 }
 
 // Wraps a workspace and tmux session together.
@@ -249,7 +475,7 @@ func (a *API) Session(w *Workspace) *Session {
 	if s == nil {
 		return nil
 	}
-	return newSession(s, w)
+	return newSession(a.adjustSession(s), w)
 }
 
 // Creates and/or attaches to the workspace session.
@@ -260,11 +486,20 @@ func (a *API) OpenWorkspace(w *Workspace) error {
 	// attach to the exist existing if there is one
 	existing := a.Session(w)
 	if existing != nil {
-		return existing.Attach()
+		return a.AttachSession(existing)
+	}
+
+	// load the layout before creating the session so that an invalid layout does not leave a session behind
+	layout, err := w.Layout()
+	if err != nil {
+		return err
 	}
 
 	// create a new session
-	path := w.Path()
+	path := w.RealPath()
+	if layout != nil {
+		path = layout.startDir(path)
+	}
 	name := w.TmuxName()
 	session, err := a.tmux.NewSession(&gotmux.SessionOptions{
 		Name:           name,
@@ -274,7 +509,41 @@ func (a *API) OpenWorkspace(w *Workspace) error {
 		return err
 	}
 
-	return session.Attach()
+	// apply the layout, a session left half built would be attached to as is
+	if layout != nil {
+		if err := a.applyLayout(session, w.RealPath(), layout); err != nil {
+			session.Kill()
+			return err
+		}
+	}
+
+	return a.AttachSession(newSession(session, w))
+}
+
+// Attaches to the session and records the visit for frecency ranking.
+// If already inside tmux, the current client is switched to the session instead.
+func (a *API) AttachSession(s *Session) error {
+	a.recordVisit(s)
+	if IsTmuxSession() {
+		return a.SwitchSession(s)
+	}
+
+	return s.Attach()
+}
+
+// Switches the current tmux client to the session.
+func (a *API) SwitchSession(s *Session) error {
+	// '=' prefix ensures an exact match on the session name
+	args := []string{"switch-client", "-t", "=" + s.Name}
+	if client := a.currentClient(); client != "" {
+		args = append(args, "-c", client)
+	}
+
+	if _, err := a.tmux.Command(args...); err != nil {
+		return errors.New("failed to switch to session " + s.DisplayName())
+	}
+
+	return nil
 }
 
 func (a *API) NewSession(name string) (*Session, error) {
@@ -297,7 +566,20 @@ func (a *API) SessionByName(name string) (*Session, error) {
 		return nil, nil
 	}
 
-	return newSession(session, nil), nil
+	return newSession(a.adjustSession(session), nil), nil
+}
+
+// Kills the session and forgets its saved snapshot.
+func (a *API) KillSession(s *Session) error {
+	if err := s.Kill(); err != nil {
+		return err
+	}
+
+	if s.Workspace != nil {
+		return a.snapshots.Remove(s.Workspace.ShortPath())
+	}
+
+	return nil
 }
 
 // Returns the number of workspaces active workspace sessions.
@@ -318,7 +600,7 @@ func (a *API) AllSessions() []*Session {
 	out := make([]*Session, len(sessions))
 	for i, s := range sessions {
 		associatedWorkspace := wMap[s.Name]
-		out[i] = newSession(s, associatedWorkspace)
+		out[i] = newSession(a.adjustSession(s), associatedWorkspace)
 	}
 
 	return out
@@ -329,11 +611,47 @@ func (a *API) SessionMap() SessionMap {
 	sMap := make(SessionMap)
 	sessions, _ := a.tmux.ListSessions()
 	for _, s := range sessions {
-		sMap[s.Name] = s
+		sMap[s.Name] = a.adjustSession(s)
 	}
 	return sMap
 }
 
+// Returns the tty of the client displaying the pane mynav runs in, empty if unknown.
+// The client is passed explicitly so that tmux does not pick a control mode client.
+func (a *API) currentClient() string {
+	pane := os.Getenv("TMUX_PANE")
+	if pane == "" {
+		return ""
+	}
+
+	session, err := a.tmux.Command("display-message", "-p", "-t", pane, "#{session_name}")
+	if err != nil {
+		return ""
+	}
+
+	out, err := a.tmux.Command("list-clients", "-t", "="+strings.TrimSpace(session), "-F", "#{client_control_mode} #{client_activity} #{client_tty}")
+	if err != nil {
+		return ""
+	}
+
+	// most recently active client that is not in control mode
+	client := ""
+	latest := ""
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 || fields[0] == "1" {
+			continue
+		}
+
+		if fields[1] > latest {
+			latest = fields[1]
+			client = fields[2]
+		}
+	}
+
+	return client
+}
+
 func IsTmuxSession() bool {
 	return os.Getenv("TMUX") != ""
 }
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// PaneCapture is the content of a pane, with its escape sequences, and the position of its cursor.
+type PaneCapture struct {
+	Content string
+
+	// cell of the cursor, -1 if the cursor is hidden
+	CursorX int
+	CursorY int
+}
+
+// Captures the visible content of the pane, preserving colors and attributes.
+func (a *API) CapturePane(p *gotmux.Pane) (*PaneCapture, error) {
+	content, err := p.Capture()
+	if err != nil {
+		return nil, err
+	}
+
+	capture := &PaneCapture{
+		Content: strings.TrimSuffix(content, "\n"),
+		CursorX: -1,
+		CursorY: -1,
+	}
+
+	x, y, _, ok := a.paneCursor(p)
+	if ok {
+		capture.CursorX = x
+		capture.CursorY = y
+	}
+
+	return capture, nil
+}
+
+// Captures the whole history of the pane followed by its visible content, preserving colors and attributes.
+func (a *API) CapturePaneHistory(p *gotmux.Pane) (*PaneCapture, error) {
+	content, err := a.tmux.Command("capture-pane", "-p", "-e", "-S", "-", "-E", "-", "-t", p.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	capture := &PaneCapture{
+		Content: strings.TrimSuffix(content, "\n"),
+		CursorX: -1,
+		CursorY: -1,
+	}
+
+	x, y, history, ok := a.paneCursor(p)
+	if ok {
+		capture.CursorX = x
+		capture.CursorY = history + y
+	}
+
+	return capture, nil
+}
+
+// Returns the cursor of the pane and the number of lines in its history.
+// The cursor is not reported while the pane is in a mode (e.g. copy mode) since the capture is of the screen below it.
+func (a *API) paneCursor(p *gotmux.Pane) (x int, y int, history int, ok bool) {
+	out, err := a.tmux.Command("display-message", "-p", "-t", p.Id, "#{cursor_x} #{cursor_y} #{history_size} #{cursor_flag} #{pane_in_mode}")
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	var visible, inMode int
+	if _, err := fmt.Sscan(out, &x, &y, &history, &visible, &inMode); err != nil {
+		return 0, 0, 0, false
+	}
+
+	return x, y, history, visible == 1 && inMode == 0
+}
+
+// Copies the text to a tmux paste buffer, which tmux forwards to the clipboard of the terminal if set-clipboard is on.
+func (a *API) CopyToTmuxBuffer(s string) error {
+	_, err := a.tmux.Command("set-buffer", "-w", "--", s)
+	return err
+}
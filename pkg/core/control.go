@@ -0,0 +1,229 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// ControlEvent is a change reported by tmux in control mode.
+type ControlEvent int
+
+const (
+	// output was written to a pane of the watched session
+	ControlOutput ControlEvent = iota
+
+	// sessions, windows or panes were created, closed, renamed or attached
+	ControlLayout
+)
+
+// ControlClient is a tmux control mode client (tmux -C) attached to the watched session.
+// It reports pane output and changes of sessions and windows as they happen, instead of polling tmux.
+// The client is read-only and does not affect the size of the session.
+type ControlClient struct {
+	// called from the reading goroutine for every event
+	onEvent func(ControlEvent)
+
+	// running control mode process, nil if not running
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	// name of the watched session
+	session string
+
+	// last attached time of the sessions before the client attached to them.
+	// The client attaching should not count as the session being attached, so this is reported instead
+	// until another client attaches to the session.
+	lastAttached map[string]string
+
+	mu sync.Mutex
+}
+
+func newControlClient(onEvent func(ControlEvent)) *ControlClient {
+	return &ControlClient{
+		onEvent:      onEvent,
+		lastAttached: make(map[string]string),
+	}
+}
+
+// Attaches the client to the session, starting the client if it is not running.
+func (c *ControlClient) watch(tmux *gotmux.Tmux, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cmd != nil && c.session == name {
+		return nil
+	}
+
+	if _, ok := c.lastAttached[name]; !ok {
+		// the trailing ':' makes the target a session rather than a pane
+		out, err := tmux.Command("display-message", "-p", "-t", "="+name+":", "#{session_last_attached}")
+		if err != nil {
+			return err
+		}
+		c.lastAttached[name] = strings.TrimSpace(out)
+	}
+
+	c.session = name
+	if c.cmd != nil {
+		_, err := io.WriteString(c.stdin, "switch-client -t "+strconv.Quote("="+name)+"\n")
+		return err
+	}
+
+	cmd := exec.Command("tmux", "-C", "attach-session", "-t", "="+name, "-f", "ignore-size,read-only")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	c.cmd = cmd
+	c.stdin = stdin
+	go c.read(cmd, stdout)
+	return nil
+}
+
+// Reads the notifications of the control mode process until it exits.
+func (c *ControlClient) read(cmd *exec.Cmd, stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		name, args, _ := strings.Cut(line, " ")
+		switch name {
+		case "%output", "%extended-output":
+			c.onEvent(ControlOutput)
+		case "%session-changed":
+			// tmux moves the client to another session when the watched one is killed,
+			// the client is stopped rather than counting as attached to a session that was not chosen
+			fields := strings.SplitN(args, " ", 2)
+			c.mu.Lock()
+			if len(fields) == 2 && c.cmd == cmd && fields[1] != c.session {
+				c.stopLocked()
+			}
+			c.mu.Unlock()
+		case "%client-session-changed":
+			// another client attached to the session, its last attached time is now accurate
+			fields := strings.SplitN(args, " ", 3)
+			if len(fields) == 3 {
+				c.mu.Lock()
+				delete(c.lastAttached, fields[2])
+				c.mu.Unlock()
+			}
+			c.onEvent(ControlLayout)
+		case "%sessions-changed",
+			"%session-renamed",
+			"%session-window-changed",
+			"%window-add",
+			"%window-close",
+			"%window-renamed",
+			"%window-pane-changed",
+			"%unlinked-window-add",
+			"%unlinked-window-close",
+			"%unlinked-window-renamed",
+			"%layout-change":
+			c.onEvent(ControlLayout)
+		}
+	}
+
+	cmd.Wait()
+
+	c.mu.Lock()
+	if c.cmd == cmd {
+		c.cmd = nil
+		c.stdin = nil
+		c.session = ""
+	}
+	c.mu.Unlock()
+
+	// the watched session was most likely killed
+	c.onEvent(ControlLayout)
+}
+
+// Returns if the client is attached to a session, changes are only reported while it is.
+func (c *ControlClient) running() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cmd != nil
+}
+
+// Stops the client.
+func (c *ControlClient) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopLocked()
+}
+
+func (c *ControlClient) stopLocked() {
+	if c.cmd == nil {
+		return
+	}
+
+	c.stdin.Close()
+	c.cmd.Process.Kill()
+	c.cmd = nil
+	c.stdin = nil
+	c.session = ""
+}
+
+// Hides the client from the session so that it is not seen as attached by a user.
+func (c *ControlClient) adjust(s *gotmux.Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if lastAttached, ok := c.lastAttached[s.Name]; ok {
+		s.LastAttached = lastAttached
+	}
+
+	if c.cmd != nil && c.session == s.Name && s.Attached > 0 {
+		s.Attached--
+	}
+}
+
+// Starts reporting changes of tmux to onEvent, which is called from a separate goroutine.
+// Output is only reported for the session passed to WatchSession.
+func (a *API) StartControl(onEvent func(ControlEvent)) {
+	a.control = newControlClient(onEvent)
+}
+
+// Reports the output of the session, nil to keep watching the current session.
+func (a *API) WatchSession(s *Session) error {
+	if a.control == nil || s == nil {
+		return nil
+	}
+
+	return a.control.watch(a.tmux, s.Name)
+}
+
+// Returns if changes of tmux are being reported. They are not while no session is watched,
+// e.g. when there are no sessions or the watched session was killed.
+func (a *API) ControlRunning() bool {
+	return a.control != nil && a.control.running()
+}
+
+// Stops reporting changes of tmux.
+func (a *API) StopControl() {
+	if a.control != nil {
+		a.control.stop()
+	}
+}
+
+// Hides the control client from the session.
+func (a *API) adjustSession(s *gotmux.Session) *gotmux.Session {
+	if a.control != nil && s != nil {
+		a.control.adjust(s)
+	}
+
+	return s
+}
@@ -2,8 +2,10 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Filesystem struct {
@@ -18,41 +20,76 @@ func newFilesystem(path string) *Filesystem {
 	return c
 }
 
+// Creates a topic, the name may be nested (e.g. "org/team") in which case the missing parent topics are created.
 func (c *Filesystem) CreateTopic(name string) (*Topic, error) {
-	t := newTopic(c.path, name)
-	if err := CreateDir(t.Path()); err != nil {
+	if err := validateTopicName(name); err != nil {
 		return nil, err
 	}
-	return t, nil
+
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		t := newTopic(c.path, strings.Join(parts[:i+1], "/"))
+		if Exists(t.Path()) {
+			if i > 0 && !isTopicDir(t.Path()) {
+				return nil, fmt.Errorf("%s is a workspace", t.Name)
+			}
+			if i == len(parts)-1 {
+				return nil, fmt.Errorf("topic %s already exists", t.Name)
+			}
+			continue
+		}
+
+		if err := CreateDir(t.Path()); err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			if err := markTopicDir(t.Path()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return newTopic(c.path, name), nil
 }
 
+// Renames the topic, the name is the full name of the topic which allows moving it under another topic.
 func (c *Filesystem) RenameTopic(t *Topic, name string) error {
-	if name == "" {
-		return errors.New("name must not be empty")
+	if err := validateTopicName(name); err != nil {
+		return err
 	}
 
-	oldPath := t.Path()
-	t.Name = name
-	newPath := t.Path()
+	if t.Contains(name) && name != t.Name {
+		return fmt.Errorf("topic %s cannot be moved inside itself", t.Name)
+	}
 
-	if err := os.Rename(oldPath, newPath); err != nil {
-		return err
+	newTopic := newTopic(c.path, name)
+	if Exists(newTopic.Path()) {
+		return fmt.Errorf("topic %s already exists", name)
 	}
 
-	return nil
-}
+	parent := newTopic.Parent()
+	if parent != "" && c.Topic(parent) == nil {
+		return fmt.Errorf("topic %s does not exist", parent)
+	}
 
-func (c *Filesystem) DeleteTopic(t *Topic) error {
-	if err := os.RemoveAll(t.Path()); err != nil {
+	if err := os.Rename(t.Path(), newTopic.Path()); err != nil {
 		return err
 	}
 
+	if parent != "" {
+		if err := markTopicDir(newTopic.Path()); err != nil {
+			return err
+		}
+	}
+
+	t.Name = name
 	return nil
 }
 
 func (c *Filesystem) CreateWorkspace(t *Topic, name string) (*Workspace, error) {
-	if name == "" {
-		return nil, errors.New("name must not be empty")
+	if err := c.ValidateWorkspaceName(t, name); err != nil {
+		return nil, err
 	}
 
 	// name = strings.ReplaceAll(name, ".", "_")
@@ -63,12 +100,69 @@ func (c *Filesystem) CreateWorkspace(t *Topic, name string) (*Workspace, error)
 	return w, nil
 }
 
+// Returns an error if a workspace cannot be created with this name in the topic.
+func (c *Filesystem) ValidateWorkspaceName(t *Topic, name string) error {
+	if err := validateWorkspaceName(name); err != nil {
+		return err
+	}
+
+	path := newWorkspace(t, name).Path()
+	if isTopicDir(path) {
+		return fmt.Errorf("topic %s already exists in topic %s", name, t.Name)
+	}
+
+	if Exists(path) {
+		return fmt.Errorf("workspace %s already exists in topic %s", name, t.Name)
+	}
+
+	return nil
+}
+
+// Creates a workspace in the topic as a symlink to the target directory.
+func (c *Filesystem) LinkWorkspace(t *Topic, target string, name string) (*Workspace, error) {
+	if err := c.ValidateWorkspaceName(t, name); err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", target)
+	}
+
+	w := newWorkspace(t, name)
+	if err := os.Symlink(target, w.Path()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Creates a workspace in the topic by moving the directory at source into it.
+func (c *Filesystem) AdoptWorkspace(t *Topic, source string, name string) (*Workspace, error) {
+	if err := c.ValidateWorkspaceName(t, name); err != nil {
+		return nil, err
+	}
+
+	w := newWorkspace(t, name)
+	if err := os.Rename(source, w.Path()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
 func (c *Filesystem) MoveWorkspace(w *Workspace, topic *Topic) error {
 	if w.Topic.Name == topic.Name {
 		return errors.New("workspace is already in this topic")
 	}
 
-	if err := os.Rename(w.Path(), filepath.Join(c.path, topic.Name, w.Name)); err != nil {
+	newPath := filepath.Join(topic.Path(), w.Name)
+	if Exists(newPath) {
+		return fmt.Errorf("workspace %s already exists in topic %s", w.Name, topic.Name)
+	}
+
+	if err := os.Rename(w.Path(), newPath); err != nil {
 		return err
 	}
 
@@ -77,30 +171,31 @@ func (c *Filesystem) MoveWorkspace(w *Workspace, topic *Topic) error {
 }
 
 func (c *Filesystem) RenameWorkspace(w *Workspace, name string) error {
-	if name == "" {
-		return errors.New("name must not be empty")
+	if err := validateWorkspaceName(name); err != nil {
+		return err
 	}
 
 	// name = strings.ReplaceAll(name, ".", "_")
 
-	oldPath := w.Path()
-	w.Name = name
-	newPath := w.Path()
+	newPath := newWorkspace(w.Topic, name).Path()
+	if Exists(newPath) {
+		return fmt.Errorf("workspace %s already exists in topic %s", name, w.Topic.Name)
+	}
 
-	if err := os.Rename(oldPath, newPath); err != nil {
+	if err := os.Rename(w.Path(), newPath); err != nil {
 		return err
 	}
 
+	w.Name = name
 	return nil
 }
 
-func (c *Filesystem) DeleteWorkspace(w *Workspace) error {
-	if err := os.RemoveAll(w.Path()); err != nil {
-		return err
-	}
-	return nil
+// Permanently removes the workspace directory.
+func (c *Filesystem) RemoveWorkspace(w *Workspace) error {
+	return os.RemoveAll(w.Path())
 }
 
+// Returns all the topics, each followed by its sub-topics.
 func (c *Filesystem) Topics() Topics {
 	topics := make(Topics, 0)
 	for _, topicDir := range GetDirEntries(c.path) {
@@ -111,14 +206,45 @@ func (c *Filesystem) Topics() Topics {
 		topicName := topicDir.Name()
 		topic := newTopic(c.path, topicName)
 		topics = append(topics, topic)
+		topics = append(topics, c.subTopics(topic)...)
+	}
+	return topics
+}
+
+// Returns the sub-topics of the topic at any depth, each followed by its sub-topics.
+func (c *Filesystem) subTopics(t *Topic) Topics {
+	topics := make(Topics, 0)
+	for _, dirEntry := range GetDirEntries(t.Path()) {
+		if !dirEntry.IsDir() || dirEntry.Name() == ".mynav" {
+			continue
+		}
+
+		if !isTopicDir(filepath.Join(t.Path(), dirEntry.Name())) {
+			continue
+		}
+
+		topic := newTopic(c.path, filepath.Join(t.Name, dirEntry.Name()))
+		topics = append(topics, topic)
+		topics = append(topics, c.subTopics(topic)...)
 	}
 	return topics
 }
 
+// Returns the workspaces directly in the topic, the workspaces of its sub-topics are not included.
+// Symlinks to directories are linked workspaces.
 func (c *Filesystem) Workspaces(t *Topic) Workspaces {
 	workspaces := make(Workspaces, 0)
 	for _, dirEntry := range GetDirEntries(t.Path()) {
-		if !dirEntry.IsDir() {
+		if dirEntry.Name() == ".mynav" {
+			continue
+		}
+
+		path := filepath.Join(t.Path(), dirEntry.Name())
+		if dirEntry.Mode()&os.ModeSymlink != 0 {
+			if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+				continue
+			}
+		} else if !dirEntry.IsDir() || isTopicDir(path) {
 			continue
 		}
 
@@ -128,25 +254,44 @@ func (c *Filesystem) Workspaces(t *Topic) Workspaces {
 	return workspaces
 }
 
+func (f *Filesystem) Topic(name string) *Topic {
+	if validateTopicName(name) != nil {
+		return nil
+	}
+
+	topicPath := filepath.Join(f.path, name)
+	if !Exists(topicPath) {
+		return nil
+	}
+
+	// every level below the top level must be marked as a topic
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		if !isTopicDir(filepath.Join(f.path, filepath.Join(parts[:i+1]...))) {
+			return nil
+		}
+	}
+
+	return newTopic(f.path, name)
+}
+
 func (f *Filesystem) Workspace(shortPath string) *Workspace {
 	topicName, workspaceName := filepath.Dir(shortPath), filepath.Base(shortPath)
-	workspacePath := filepath.Join(f.path, topicName, workspaceName)
-	if !Exists(workspacePath) {
+	topic := f.Topic(topicName)
+	if topic == nil || workspaceName == ".mynav" {
+		return nil
+	}
+
+	w := newWorkspace(topic, workspaceName)
+	if !Exists(w.Path()) || (!w.Linked() && isTopicDir(w.Path())) {
 		return nil
 	}
 
-	return newWorkspace(newTopic(f.path, topicName), workspaceName)
+	return w
 }
 
 func (c *Filesystem) TopicsCount() int {
-	dirs := GetDirEntries(c.path)
-	count := 0
-	for _, fi := range dirs {
-		if fi.IsDir() {
-			count++
-		}
-	}
-	return count
+	return len(c.Topics())
 }
 
 func (c *Filesystem) AllWorkspaces() Workspaces {
@@ -160,16 +305,48 @@ func (c *Filesystem) AllWorkspaces() Workspaces {
 }
 
 func (c *Filesystem) WorkspacesCount() int {
-	dirs := GetDirEntries(c.path)
-	count := 0
-	for _, topic := range dirs {
-		if topic.IsDir() {
-			for _, workspace := range GetDirEntries(filepath.Join(c.path, topic.Name())) {
-				if workspace.IsDir() {
-					count++
-				}
-			}
+	return len(c.AllWorkspaces())
+}
+
+// Returns if the directory is marked as a sub-topic.
+func isTopicDir(path string) bool {
+	return Exists(filepath.Join(path, TopicMarker))
+}
+
+// Marks the directory as a sub-topic.
+func markTopicDir(path string) error {
+	return os.WriteFile(filepath.Join(path, TopicMarker), nil, 0644)
+}
+
+// Returns an error if the name cannot be used for a topic.
+func validateTopicName(name string) error {
+	if name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if filepath.IsAbs(name) || filepath.Clean(name) != name {
+		return fmt.Errorf("invalid topic name %s", name)
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." || part == "." || part == ".mynav" {
+			return fmt.Errorf("invalid topic name %s", name)
 		}
 	}
-	return count
+
+	return nil
+}
+
+// Returns an error if the name cannot be used for a workspace.
+// A workspace name is a single directory in its topic, so that the workspace cannot escape it.
+func validateWorkspaceName(name string) error {
+	if name == "" {
+		return errors.New("name must not be empty")
+	}
+
+	if strings.ContainsRune(name, filepath.Separator) || name == "." || name == ".." || name == ".mynav" {
+		return fmt.Errorf("invalid workspace name %s", name)
+	}
+
+	return nil
 }
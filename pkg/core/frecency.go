@@ -0,0 +1,211 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Time after which a visit counts for half as much in the frecency score.
+const FrecencyHalfLife = 7 * 24 * time.Hour
+
+// Visits records how often and how recently a workspace or session was opened.
+type Visits struct {
+	Count int       `json:"count"`
+	Last  time.Time `json:"last"`
+
+	// frecency at the time of the last visit
+	Score float64 `json:"score"`
+}
+
+// Returns the visits with a visit at the given time added.
+// Each visit adds 1 to the score, which decays by half every FrecencyHalfLife.
+func (v *Visits) visit(now time.Time) *Visits {
+	if v == nil {
+		return &Visits{Count: 1, Last: now, Score: 1}
+	}
+
+	return &Visits{
+		Count: v.Count + 1,
+		Last:  now,
+		Score: v.Frecency(now) + 1,
+	}
+}
+
+// Returns the frecency score at the given time, 0 if never visited.
+func (v *Visits) Frecency(now time.Time) float64 {
+	if v == nil {
+		return 0
+	}
+
+	elapsed := max(now.Sub(v.Last), 0)
+	return v.Score * math.Pow(0.5, float64(elapsed)/float64(FrecencyHalfLife))
+}
+
+// SortMode is the order of the rows of a view.
+type SortMode string
+
+const (
+	// most frecent first, most recent first for the rest
+	SortFrecency SortMode = "frecency"
+
+	// most recent first (last modified for topics and workspaces, last attached for sessions)
+	SortRecent SortMode = "recent"
+
+	// alphabetical
+	SortName SortMode = "name"
+)
+
+// Sort modes in the order they are cycled.
+var SortModes = []SortMode{SortFrecency, SortRecent, SortName}
+
+// Returns the sort mode after this one.
+func (m SortMode) Next() SortMode {
+	for i, mode := range SortModes {
+		if mode == m {
+			return SortModes[(i+1)%len(SortModes)]
+		}
+	}
+
+	return SortModes[0]
+}
+
+// Returns the sort mode by name, an error if it is not a sort mode.
+func ParseSortMode(s string) (SortMode, error) {
+	for _, mode := range SortModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+
+	names := make([]string, len(SortModes))
+	for i, mode := range SortModes {
+		names[i] = string(mode)
+	}
+	return "", fmt.Errorf("invalid sort mode %s, expected one of %s", s, strings.Join(names, ", "))
+}
+
+// Returns the frecency of the workspace.
+func (a *API) WorkspaceFrecency(w *Workspace) float64 {
+	return a.local.ConfigData().WorkspaceVisits[w.ShortPath()].Frecency(time.Now())
+}
+
+// Returns the frecency of the topic, the sum of the frecency of its workspaces.
+func (a *API) TopicFrecency(t *Topic) float64 {
+	now := time.Now()
+	visits := a.local.ConfigData().WorkspaceVisits
+	prefix := t.Name + "/"
+	score := 0.0
+	for shortPath, v := range visits {
+		if strings.HasPrefix(shortPath, prefix) {
+			score += v.Frecency(now)
+		}
+	}
+	return score
+}
+
+// Returns the frecency of the session, the frecency of its workspace if it has one.
+func (a *API) SessionFrecency(s *Session) float64 {
+	if s.Workspace != nil {
+		return a.WorkspaceFrecency(s.Workspace)
+	}
+
+	return a.local.ConfigData().SessionVisits[s.Name].Frecency(time.Now())
+}
+
+// Records a visit of the session, and of its workspace if it has one, and adds it to the navigation history.
+func (a *API) recordVisit(s *Session) {
+	a.pushHistory(s)
+	if s.Workspace != nil {
+		a.local.RecordWorkspaceVisit(s.Workspace.ShortPath(), time.Now())
+		return
+	}
+
+	a.local.RecordSessionVisit(s.Name, time.Now())
+}
+
+// Returns the persisted sort mode of the view, SortFrecency if unset.
+func (a *API) SortMode(view string) SortMode {
+	mode, err := ParseSortMode(string(a.local.ConfigData().SortModes[view]))
+	if err != nil {
+		return SortFrecency
+	}
+	return mode
+}
+
+// Persists the sort mode of the view.
+func (a *API) SetSortMode(view string, mode SortMode) {
+	a.local.SetSortMode(view, mode)
+}
+
+// Returns the topics sorted by the mode.
+func (a *API) SortTopics(topics Topics, mode SortMode) Topics {
+	switch mode {
+	case SortName:
+		sort.SliceStable(topics, func(i, j int) bool {
+			return topics[i].Name < topics[j].Name
+		})
+	case SortFrecency:
+		topics = topics.Sorted()
+		scores := make(map[*Topic]float64, len(topics))
+		for _, t := range topics {
+			scores[t] = a.TopicFrecency(t)
+		}
+		sort.SliceStable(topics, func(i, j int) bool {
+			return scores[topics[i]] > scores[topics[j]]
+		})
+	default:
+		topics = topics.Sorted()
+	}
+	return topics
+}
+
+// Returns the workspaces sorted by the mode.
+func (a *API) SortWorkspaces(workspaces Workspaces, mode SortMode) Workspaces {
+	switch mode {
+	case SortName:
+		sort.SliceStable(workspaces, func(i, j int) bool {
+			return workspaces[i].ShortPath() < workspaces[j].ShortPath()
+		})
+	case SortFrecency:
+		workspaces = workspaces.Sorted()
+		scores := make(map[*Workspace]float64, len(workspaces))
+		for _, w := range workspaces {
+			scores[w] = a.WorkspaceFrecency(w)
+		}
+		sort.SliceStable(workspaces, func(i, j int) bool {
+			return scores[workspaces[i]] > scores[workspaces[j]]
+		})
+	default:
+		workspaces = workspaces.Sorted()
+	}
+	return workspaces
+}
+
+// Returns the sessions sorted by the mode.
+func (a *API) SortSessions(sessions []*Session, mode SortMode) []*Session {
+	// most recently attached first
+	sort.SliceStable(sessions, func(i, j int) bool {
+		t1 := UnixTime(sessions[i].LastAttached)
+		t2 := UnixTime(sessions[j].LastAttached)
+		return t1.After(t2)
+	})
+
+	switch mode {
+	case SortName:
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return sessions[i].DisplayName() < sessions[j].DisplayName()
+		})
+	case SortFrecency:
+		scores := make(map[*Session]float64, len(sessions))
+		for _, s := range sessions {
+			scores[s] = a.SessionFrecency(s)
+		}
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return scores[sessions[i]] > scores[sessions[j]]
+		})
+	}
+	return sessions
+}
@@ -0,0 +1,199 @@
+package core
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Scores of the fuzzy matcher.
+// A match is rewarded, more so at the start of a path segment or a word, and gaps between matched characters are penalized.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// the character follows a '/'
+	bonusPathSegment = 10
+
+	// the character starts the candidate or follows a separator (e.g. space, '-', '_', '.')
+	bonusBoundary = 8
+
+	// the character is an uppercase letter following a lowercase letter, or a digit following a letter
+	bonusCamel = 7
+
+	// the character follows the previous matched character
+	bonusConsecutive = 5
+
+	// bonuses of the first character of the pattern count this many times
+	firstCharMultiplier = 2
+)
+
+// FuzzyMatch is a candidate matched by a pattern.
+type FuzzyMatch struct {
+	// index of the candidate in the list
+	Index int
+
+	// higher is better
+	Score int
+
+	// indexes of the matched runes of the candidate, in order
+	Positions []int
+}
+
+// Matches the pattern against the candidate, returns nil if the characters of the pattern are not all found in order.
+// Matching is case insensitive unless the pattern contains an uppercase letter.
+// The best scoring alignment of the pattern is found, rather than the first one.
+func FuzzyMatchString(pattern string, candidate string) *FuzzyMatch {
+	p := []rune(pattern)
+	c := []rune(candidate)
+	if len(p) == 0 {
+		return &FuzzyMatch{Positions: []int{}}
+	}
+	if len(p) > len(c) {
+		return nil
+	}
+
+	caseSensitive := false
+	for _, r := range p {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+
+	eq := func(a rune, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	bonuses := make([]int, len(c))
+	for j := range c {
+		bonuses[j] = fuzzyBonus(c, j)
+	}
+
+	// score[i][j] is the best score of the pattern up to i with p[i] matched at c[j], invalid if unset.
+	// from[i][j] is the position of p[i-1] in that alignment.
+	const invalid = -1 << 30
+	score := make([][]int, len(p))
+	from := make([][]int, len(p))
+	for i := range p {
+		score[i] = make([]int, len(c))
+		from[i] = make([]int, len(c))
+		for j := range c {
+			score[i][j] = invalid
+		}
+	}
+
+	for i := range p {
+		// best previous alignment followed by a gap, with the position it ends at
+		gap, gapFrom := invalid, -1
+		for j := i; j < len(c); j++ {
+			if i > 0 && j >= 2 {
+				if gap != invalid {
+					gap += scoreGapExtension
+				}
+				if prev := score[i-1][j-2]; prev != invalid && prev+scoreGapStart > gap {
+					gap, gapFrom = prev+scoreGapStart, j-2
+				}
+			}
+
+			if !eq(p[i], c[j]) {
+				continue
+			}
+
+			if i == 0 {
+				score[i][j] = scoreMatch + bonuses[j]*firstCharMultiplier
+				continue
+			}
+
+			best, bestFrom := gap, gapFrom
+			if prev := score[i-1][j-1]; prev != invalid && prev+bonusConsecutive >= best {
+				best, bestFrom = prev+bonusConsecutive, j-1
+			}
+			if best == invalid {
+				continue
+			}
+
+			score[i][j] = best + scoreMatch + bonuses[j]
+			from[i][j] = bestFrom
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for j := range c {
+		if score[last][j] != invalid && (end == -1 || score[last][j] > score[last][end]) {
+			end = j
+		}
+	}
+	if end == -1 {
+		return nil
+	}
+
+	positions := make([]int, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+
+	return &FuzzyMatch{
+		Score:     score[last][end],
+		Positions: positions,
+	}
+}
+
+// Returns the bonus of matching the rune at the index of the candidate.
+func fuzzyBonus(c []rune, j int) int {
+	if j == 0 {
+		return bonusBoundary
+	}
+
+	prev, cur := c[j-1], c[j]
+	switch {
+	case prev == '/':
+		return bonusPathSegment
+	case !isWordRune(prev) && isWordRune(cur):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	case unicode.IsLetter(prev) && unicode.IsDigit(cur):
+		return bonusCamel
+	}
+
+	return 0
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Returns the candidates matched by the pattern, best match first.
+// Ties are broken by the length of the candidate, then by its index.
+// With an empty pattern every candidate is returned in order.
+func FuzzyFilter(candidates []string, pattern string) []*FuzzyMatch {
+	matches := make([]*FuzzyMatch, 0)
+	for i, candidate := range candidates {
+		m := FuzzyMatchString(pattern, candidate)
+		if m == nil {
+			continue
+		}
+
+		m.Index = i
+		matches = append(matches, m)
+	}
+
+	if pattern == "" {
+		return matches
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+
+		return len(candidates[matches[i].Index]) < len(candidates[matches[j].Index])
+	})
+	return matches
+}
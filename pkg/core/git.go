@@ -1,7 +1,14 @@
 package core
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -15,7 +22,231 @@ func GitRemote(path string) (string, error) {
 	return gitRemote, nil
 }
 
-func GitClone(url string, path string) error {
-	exec.Command("git", "clone", url, path).Run()
+// CloneOptions configures a git clone.
+type CloneOptions struct {
+	// branch to check out, the remote default if empty
+	Branch string
+
+	// depth of a shallow clone, full history if 0
+	Depth int
+
+	// if submodules are cloned recursively
+	Submodules bool
+}
+
+// Returns the arguments passed to git clone.
+func (o *CloneOptions) args() []string {
+	args := make([]string, 0)
+	if o == nil {
+		return args
+	}
+
+	if o.Branch != "" {
+		args = append(args, "--branch", o.Branch)
+	}
+
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+
+	if o.Submodules {
+		args = append(args, "--recurse-submodules")
+		if o.Depth > 0 {
+			args = append(args, "--shallow-submodules")
+		}
+	}
+
+	return args
+}
+
+// Clones the repo at url into path, opts can be nil.
+// Progress reported by git is passed to onProgress if not nil.
+// The clone is aborted if ctx is cancelled. On failure the error contains the git error output.
+func GitClone(ctx context.Context, url string, path string, opts *CloneOptions, onProgress func(string)) error {
+	args := append([]string{"clone", "--progress"}, opts.args()...)
+	args = append(args, "--", url, path)
+	cmd := exec.CommandContext(ctx, "git", args...)
+
+	// never prompt for credentials as there is no terminal to answer
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// git reports progress on stderr, updating lines with carriage returns
+	message := ""
+	scanner := bufio.NewScanner(stderr)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// the first error is the cause, following ones are generic
+		isError := strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:")
+		if isError && message == "" {
+			message = line
+		}
+
+		if onProgress != nil {
+			onProgress(line)
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return errors.New("clone cancelled")
+	}
+
+	if err != nil {
+		if message == "" {
+			return fmt.Errorf("git clone failed: %w", err)
+		}
+		return errors.New(message)
+	}
+
 	return nil
 }
+
+// Splits on both carriage returns and new lines.
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// Status of a git repository.
+type GitStatus struct {
+	// current branch, "(detached)" if HEAD is detached
+	Branch string
+
+	// if there are staged, unstaged or untracked changes
+	Dirty bool
+
+	// if the branch has an upstream, ahead and behind are only set if it does
+	Upstream bool
+
+	// commits ahead and behind the upstream
+	Ahead  int
+	Behind int
+}
+
+// Returns a short description of the status (e.g. "dirty +1 -2").
+func (s *GitStatus) String() string {
+	out := "clean"
+	if s.Dirty {
+		out = "dirty"
+	}
+
+	if s.Ahead > 0 {
+		out += fmt.Sprintf(" +%d", s.Ahead)
+	}
+
+	if s.Behind > 0 {
+		out += fmt.Sprintf(" -%d", s.Behind)
+	}
+
+	return out
+}
+
+// Returns the status of the repository at path.
+func GitStatusOf(path string) (*GitStatus, error) {
+	out, err := exec.Command("git", "-C", path, "status", "--porcelain=v2", "--branch").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &GitStatus{}
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "# branch.head "):
+			status.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = true
+		case strings.HasPrefix(line, "# branch.ab "):
+			fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &status.Ahead, &status.Behind)
+		case strings.HasPrefix(line, "#"):
+		default:
+			status.Dirty = true
+		}
+	}
+
+	return status, nil
+}
+
+// Naming strategies for workspaces created from a git url.
+const (
+	// name of the repo (e.g. mynav)
+	RepoNaming = "repo"
+
+	// owner and name of the repo (e.g. GianlucaP106-mynav)
+	OwnerRepoNaming = "owner-repo"
+)
+
+// Returns the workspace name for the git url following the naming strategy.
+func RepoName(url string, naming string) (string, error) {
+	owner, repo := parseRepoUrl(url)
+	if repo == "" {
+		return "", fmt.Errorf("could not find a repo name in %s", url)
+	}
+
+	switch naming {
+	case RepoNaming:
+		return repo, nil
+	case OwnerRepoNaming:
+		if owner == "" {
+			return "", fmt.Errorf("could not find a repo owner in %s", url)
+		}
+		return owner + "-" + repo, nil
+	default:
+		return "", fmt.Errorf("invalid naming strategy %s", naming)
+	}
+}
+
+// Returns the owner and the name of the repo from its url.
+// Supports urls (https://host/owner/repo.git), scp-like urls (git@host:owner/repo.git) and paths.
+func parseRepoUrl(url string) (owner string, repo string) {
+	url = strings.TrimSpace(url)
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+
+	hasHost := true
+	if idx := strings.Index(url, "://"); idx >= 0 {
+		url = url[idx+3:]
+	} else if idx := strings.Index(url, ":"); idx >= 0 {
+		url = url[:idx] + "/" + url[idx+1:]
+	} else {
+		hasHost = false
+	}
+
+	parts := strings.Split(url, "/")
+	repo = parts[len(parts)-1]
+
+	// the host is not an owner (e.g. https://host/repo)
+	if hasHost {
+		parts = parts[1:]
+	}
+	if len(parts) > 1 {
+		owner = parts[len(parts)-2]
+	}
+
+	return owner, repo
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// git status cache magic numbers
+const (
+	// how long a cached status is considered fresh
+	gitStatusTTL = 30 * time.Second
+
+	// max number of git processes running at once
+	gitStatusWorkers = 8
+)
+
+// GitStatusCache caches the git status of workspaces by path.
+// Statuses are loaded concurrently so that scanning many repositories stays fast.
+type GitStatusCache struct {
+	entries map[string]*gitStatusEntry
+	mu      sync.RWMutex
+}
+
+type gitStatusEntry struct {
+	// nil if the workspace is not a git repository
+	status  *GitStatus
+	updated time.Time
+}
+
+func newGitStatusCache() *GitStatusCache {
+	return &GitStatusCache{
+		entries: make(map[string]*gitStatusEntry),
+	}
+}
+
+// Returns the cached status of the workspace (nil if not a git repository), and if it was cached at all.
+func (c *GitStatusCache) Get(w *Workspace) (*GitStatus, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e := c.entries[w.Path()]
+	if e == nil {
+		return nil, false
+	}
+
+	return e.status, true
+}
+
+// Returns if the workspace has no cached status or if it has expired.
+func (c *GitStatusCache) stale(w *Workspace) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	e := c.entries[w.Path()]
+	return e == nil || time.Since(e.updated) > gitStatusTTL
+}
+
+func (c *GitStatusCache) set(w *Workspace, status *GitStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[w.Path()] = &gitStatusEntry{
+		status:  status,
+		updated: time.Now(),
+	}
+}
+
+// Loads the statuses of the workspaces that are not cached or expired.
+// Blocks until all the statuses are loaded, returns if any was loaded.
+func (c *GitStatusCache) Load(workspaces Workspaces) bool {
+	stale := make(Workspaces, 0)
+	for _, w := range workspaces {
+		if c.stale(w) {
+			stale = append(stale, w)
+		}
+	}
+
+	if len(stale) == 0 {
+		return false
+	}
+
+	sem := make(chan struct{}, gitStatusWorkers)
+	wg := &sync.WaitGroup{}
+	for _, w := range stale {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(w *Workspace) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if !Exists(filepath.Join(w.Path(), ".git")) {
+				c.set(w, nil)
+				return
+			}
+
+			status, _ := GitStatusOf(w.Path())
+			c.set(w, status)
+		}(w)
+	}
+	wg.Wait()
+
+	return true
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Number of entries kept in the navigation history.
+const HistorySize = 50
+
+// HistoryEntry is a visit in the navigation history, of a workspace or of a session without a workspace.
+type HistoryEntry struct {
+	// short path of the workspace, empty for a session without a workspace
+	Workspace string `json:"workspace,omitempty"`
+
+	// name of the session without a workspace
+	Session string `json:"session,omitempty"`
+}
+
+// Returns the name of the entry as shown to the user.
+func (e *HistoryEntry) Name() string {
+	if e.Workspace != "" {
+		return e.Workspace
+	}
+	return e.Session
+}
+
+func (e *HistoryEntry) equal(other *HistoryEntry) bool {
+	return other != nil && e.Workspace == other.Workspace && e.Session == other.Session
+}
+
+// Returns the entry for the session, of its workspace if it has one.
+func newHistoryEntry(s *Session) *HistoryEntry {
+	if s.Workspace != nil {
+		return &HistoryEntry{Workspace: s.Workspace.ShortPath()}
+	}
+	return &HistoryEntry{Session: s.Name}
+}
+
+// Returns the navigation history, oldest first, and the index of the current entry.
+func (a *API) History() ([]*HistoryEntry, int) {
+	data := a.local.ConfigData()
+	return data.History, data.HistoryPosition
+}
+
+// Adds the session to the history after the current entry, dropping the entries ahead of it.
+// Nothing is added if the session is the current entry (e.g. when jumping to it).
+func (a *API) pushHistory(s *Session) {
+	entry := newHistoryEntry(s)
+	history, position := a.History()
+	if position < len(history) && entry.equal(history[position]) {
+		return
+	}
+
+	if len(history) > 0 {
+		history = history[:min(position+1, len(history))]
+	}
+	history = append(slices.Clone(history), entry)
+	if len(history) > HistorySize {
+		history = history[len(history)-HistorySize:]
+	}
+	a.local.SetHistory(history, len(history)-1)
+}
+
+// Returns the closest entry that still exists before (offset -1) or after (offset 1) the current one, with its index.
+// Returns -1 and nil if there is none.
+func (a *API) HistoryTarget(offset int) (int, *HistoryEntry) {
+	history, position := a.History()
+	for i := position + offset; i >= 0 && i < len(history); i += offset {
+		if a.historyEntryExists(history[i]) {
+			return i, history[i]
+		}
+	}
+	return -1, nil
+}
+
+// Returns the last entry visited before the current one that still exists and is not the current one, nil if there is none.
+func (a *API) PreviousHistoryEntry() *HistoryEntry {
+	history, position := a.History()
+	if position >= len(history) {
+		return nil
+	}
+
+	current := history[position]
+	for i := position - 1; i >= 0; i-- {
+		if !history[i].equal(current) && a.historyEntryExists(history[i]) {
+			return history[i]
+		}
+	}
+	return nil
+}
+
+// Moves the current entry of the history to the index and opens it.
+func (a *API) JumpHistory(index int) error {
+	history, _ := a.History()
+	if index < 0 || index >= len(history) {
+		return fmt.Errorf("no history entry at %d", index)
+	}
+
+	a.local.SetHistory(history, index)
+	return a.OpenHistoryEntry(history[index])
+}
+
+// Opens the workspace or session of the entry.
+func (a *API) OpenHistoryEntry(e *HistoryEntry) error {
+	if e.Workspace != "" {
+		w := a.Workspace(e.Workspace)
+		if w == nil {
+			return fmt.Errorf("workspace %s does not exist", e.Workspace)
+		}
+		return a.OpenWorkspace(w)
+	}
+
+	s, err := a.SessionByName(e.Session)
+	if err != nil {
+		return err
+	}
+	if s == nil {
+		return fmt.Errorf("session %s does not exist", e.Session)
+	}
+	return a.AttachSession(s)
+}
+
+// Returns the workspace of the entry, nil for a session without a workspace.
+func (a *API) HistoryWorkspace(e *HistoryEntry) *Workspace {
+	if e.Workspace == "" {
+		return nil
+	}
+	return a.Workspace(e.Workspace)
+}
+
+func (a *API) historyEntryExists(e *HistoryEntry) bool {
+	if e.Workspace != "" {
+		return a.Workspace(e.Workspace) != nil
+	}
+
+	s, _ := a.SessionByName(e.Session)
+	return s != nil
+}
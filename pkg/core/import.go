@@ -0,0 +1,223 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files marking a directory as a project to import, besides a git repository.
+var ProjectMarkers = []string{
+	"go.mod",
+	"package.json",
+	"Cargo.toml",
+	"pyproject.toml",
+	"setup.py",
+	"requirements.txt",
+	"pom.xml",
+	"build.gradle",
+	"Gemfile",
+	"composer.json",
+	"mix.exs",
+	"CMakeLists.txt",
+	"Makefile",
+}
+
+// Ways of proposing the topic of an imported project.
+const (
+	// owner of the git remote, the parent directory for projects without a remote
+	GroupByOwner = "owner"
+
+	// parent directory relative to the imported directory
+	GroupByParent = "parent"
+)
+
+// ImportMode is how an imported project is added to a topic.
+type ImportMode string
+
+const (
+	// the project is moved into the topic
+	ImportMove ImportMode = "move"
+
+	// the project stays where it is and is linked into the topic
+	ImportLink ImportMode = "link"
+)
+
+// Parses an import mode, returns an error if it is not move or link.
+func ParseImportMode(s string) (ImportMode, error) {
+	switch mode := ImportMode(s); mode {
+	case ImportMove, ImportLink:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid import mode %s, expected move or link", s)
+	}
+}
+
+// ImportItem is a project found in the imported directory and the workspace it is proposed as.
+type ImportItem struct {
+	// absolute path of the project
+	Source string
+
+	// proposed topic and workspace name
+	Topic string
+	Name  string
+
+	// reason the project cannot be imported, empty if it can
+	Conflict string
+}
+
+// Returns the short path of the proposed workspace.
+func (i *ImportItem) ShortPath() string {
+	return filepath.Join(i.Topic, i.Name)
+}
+
+// Returns a line describing what importing the item does.
+func (i *ImportItem) String(mode ImportMode) string {
+	if i.Conflict != "" {
+		return fmt.Sprintf("skip %s: %s", HomeShortPath(i.Source), i.Conflict)
+	}
+	return fmt.Sprintf("%s %s -> %s", mode, HomeShortPath(i.Source), i.ShortPath())
+}
+
+// Scans the directory for projects (git repositories or directories with a project marker) and proposes a workspace for each.
+// Projects are not searched for nested projects, hidden directories are skipped.
+func (a *API) ScanImport(dir string, groupBy string) ([]*ImportItem, error) {
+	if groupBy != GroupByOwner && groupBy != GroupByParent {
+		return nil, fmt.Errorf("invalid grouping %s, expected owner or parent", groupBy)
+	}
+
+	dir, err := filepath.Abs(ExpandHome(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	if dir == a.fs.path || strings.HasPrefix(dir, a.fs.path+"/") {
+		return nil, fmt.Errorf("%s is already in the mynav root", dir)
+	}
+
+	items := make([]*ImportItem, 0)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+			return filepath.SkipDir
+		}
+
+		// the root may be inside the imported directory
+		if path == a.fs.path {
+			return filepath.SkipDir
+		}
+
+		if !isProjectDir(path) {
+			return nil
+		}
+
+		items = append(items, &ImportItem{
+			Source: path,
+			Topic:  importTopic(dir, path, groupBy),
+			Name:   d.Name(),
+		})
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.checkImport(items)
+	return items, nil
+}
+
+// Sets the conflict of the items that cannot be imported.
+func (a *API) checkImport(items []*ImportItem) {
+	proposed := make(map[string]*ImportItem)
+	for _, item := range items {
+		if err := validateTopicName(item.Topic); err != nil {
+			item.Conflict = err.Error()
+			continue
+		}
+
+		if strings.HasPrefix(a.fs.path, item.Source+"/") {
+			item.Conflict = "it contains the mynav root"
+			continue
+		}
+
+		if other := proposed[item.ShortPath()]; other != nil {
+			item.Conflict = fmt.Sprintf("%s is already proposed for %s", item.ShortPath(), HomeShortPath(other.Source))
+			continue
+		}
+		proposed[item.ShortPath()] = item
+
+		if Exists(filepath.Join(a.fs.path, item.Topic, item.Name)) {
+			item.Conflict = item.ShortPath() + " already exists"
+		}
+	}
+}
+
+// Imports the item as a workspace, creating its topic if needed.
+func (a *API) Import(item *ImportItem, mode ImportMode) (*Workspace, error) {
+	if item.Conflict != "" {
+		return nil, errors.New(item.Conflict)
+	}
+
+	t := a.Topic(item.Topic)
+	if t == nil {
+		var err error
+		if t, err = a.NewTopic(item.Topic); err != nil {
+			return nil, err
+		}
+	}
+
+	if mode == ImportLink {
+		return a.fs.LinkWorkspace(t, item.Source, item.Name)
+	}
+
+	return a.fs.AdoptWorkspace(t, item.Source, item.Name)
+}
+
+// Returns if the directory is a git repository or has a project marker.
+func isProjectDir(path string) bool {
+	if Exists(filepath.Join(path, ".git")) {
+		return true
+	}
+
+	for _, marker := range ProjectMarkers {
+		if Exists(filepath.Join(path, marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the topic proposed for the project found in the imported directory.
+func importTopic(dir string, path string, groupBy string) string {
+	if groupBy == GroupByOwner {
+		remote, _ := GitRemote(filepath.Join(path, ".git"))
+		if owner, _ := parseRepoUrl(remote); owner != "" && remote != "" {
+			return owner
+		}
+	}
+
+	// the imported directory may itself be a project
+	if path == dir {
+		return filepath.Base(filepath.Dir(dir))
+	}
+
+	parent, err := filepath.Rel(dir, filepath.Dir(path))
+	if err != nil || parent == "." {
+		return filepath.Base(dir)
+	}
+	return parent
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Name of the layout file looked up in the .mynav directory of a workspace or a topic.
+const LayoutFile = "layout.json"
+
+// Layout describes the windows and panes of a session.
+// It is applied when mynav first creates the session of a workspace.
+type Layout struct {
+	Windows []*LayoutWindow `json:"windows"`
+}
+
+// LayoutWindow describes a window of a layout.
+type LayoutWindow struct {
+	// name of the window, tmux default if empty
+	Name string `json:"name,omitempty"`
+
+	// directory of the first pane, absolute or relative to the workspace
+	Dir string `json:"dir,omitempty"`
+
+	// command sent to the first pane
+	Command string `json:"command,omitempty"`
+
+	// tmux layout applied after the panes are created (e.g. "tiled", "main-vertical" or a layout string)
+	Layout string `json:"layout,omitempty"`
+
+	// additional panes split from the previous pane
+	Panes []*LayoutPane `json:"panes,omitempty"`
+}
+
+// LayoutPane describes a pane split in a window.
+type LayoutPane struct {
+	// direction of the split, "horizontal" or "vertical" (default)
+	Split string `json:"split,omitempty"`
+
+	// directory of the pane, absolute or relative to the workspace
+	Dir string `json:"dir,omitempty"`
+
+	// command sent to the pane
+	Command string `json:"command,omitempty"`
+}
+
+// Loads the layout at path, nil if there is no layout file.
+func loadLayout(path string) (*Layout, error) {
+	if !Exists(path) {
+		return nil, nil
+	}
+
+	layout, err := LoadJson[Layout](path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layout %s: %w", path, err)
+	}
+
+	if len(layout.Windows) == 0 {
+		return nil, nil
+	}
+
+	return layout, nil
+}
+
+// Resolves a directory of the layout relative to the root.
+func (l *Layout) dir(root string, dir string) string {
+	if dir == "" {
+		return root
+	}
+
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(root, dir)
+}
+
+// Returns the start directory of the first window.
+func (l *Layout) startDir(root string) string {
+	return l.dir(root, l.Windows[0].Dir)
+}
+
+// Applies the layout to a newly created session.
+// The first window of the layout reuses the window created with the session.
+func (a *API) applyLayout(session *gotmux.Session, root string, layout *Layout) error {
+	windows, err := session.ListWindows()
+	if err != nil || len(windows) == 0 {
+		return errors.New("failed to list windows of session " + session.Name)
+	}
+	first := windows[0]
+
+	for i, lw := range layout.Windows {
+		var window *gotmux.Window
+		if i == 0 {
+			window = first
+			if lw.Name != "" {
+				if err := window.Rename(lw.Name); err != nil {
+					return err
+				}
+			}
+		} else {
+			window, err = session.NewWindow(&gotmux.NewWindowOptions{
+				StartDirectory: layout.dir(root, lw.Dir),
+				WindowName:     lw.Name,
+				DoNotAttach:    true,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := a.applyLayoutWindow(window, root, layout, lw); err != nil {
+			return err
+		}
+	}
+
+	return first.Select()
+}
+
+// Creates the panes of a window and sends their commands.
+func (a *API) applyLayoutWindow(window *gotmux.Window, root string, layout *Layout, lw *LayoutWindow) error {
+	panes, err := window.ListPanes()
+	if err != nil || len(panes) == 0 {
+		return errors.New("failed to list panes of window " + window.Name)
+	}
+
+	paneId := panes[0].Id
+	if err := a.sendCommand(paneId, lw.Command); err != nil {
+		return err
+	}
+
+	for _, lp := range lw.Panes {
+		direction := string(gotmux.PaneSplitDirectionVertical)
+		if lp.Split == "horizontal" {
+			direction = string(gotmux.PaneSplitDirectionHorizontal)
+		}
+
+		out, err := a.tmux.Command("split-window", direction, "-t", paneId, "-c", layout.dir(root, lp.Dir), "-P", "-F", "#{pane_id}")
+		if err != nil {
+			return errors.New("failed to split pane in window " + window.Name)
+		}
+
+		paneId = strings.TrimSpace(out)
+		if err := a.sendCommand(paneId, lp.Command); err != nil {
+			return err
+		}
+	}
+
+	if lw.Layout != "" {
+		if err := window.SelectLayout(gotmux.WindowLayout(lw.Layout)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Types the command in the pane and presses enter.
+func (a *API) sendCommand(paneId string, command string) error {
+	if command == "" {
+		return nil
+	}
+
+	// the command is sent literally, otherwise tmux reads a command like "Escape" or "C-c" as a key
+	if _, err := a.tmux.Command("send-keys", "-l", "-t", paneId, "--", command); err != nil {
+		return errors.New("failed to send command to pane " + paneId)
+	}
+
+	if _, err := a.tmux.Command("send-keys", "-t", paneId, "Enter"); err != nil {
+		return errors.New("failed to send command to pane " + paneId)
+	}
+
+	return nil
+}
+
+// Writes the layout to path, creating its directory if needed.
+func saveLayout(path string, layout *Layout) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
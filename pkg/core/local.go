@@ -5,12 +5,35 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // Data for the local config store.
 type LocalConfigData struct {
 	SelectedWorkspace string `json:"selected-workspace"`
+	GitColumns        bool   `json:"git-columns"`
+
+	// days deleted items are kept in the trash, DefaultTrashRetentionDays if 0, forever if negative
+	TrashRetentionDays int `json:"trash-retention-days,omitempty"`
+
+	// visits of the workspaces by short path, and of the sessions without a workspace by name
+	WorkspaceVisits map[string]*Visits `json:"workspace-visits,omitempty"`
+	SessionVisits   map[string]*Visits `json:"session-visits,omitempty"`
+
+	// sort mode of each view
+	SortModes map[string]SortMode `json:"sort-modes,omitempty"`
+
+	// tags of the workspaces by short path
+	Tags map[string][]string `json:"tags,omitempty"`
+
+	// short paths of the pinned workspaces by slot ("1" to "9")
+	Pins map[string]string `json:"pins,omitempty"`
+
+	// visited workspaces and sessions, oldest first, and the index of the current one
+	History         []*HistoryEntry `json:"history,omitempty"`
+	HistoryPosition int             `json:"history-position,omitempty"`
 }
 
 // LocalConfig is the LocalConfig configuration.
@@ -41,6 +64,13 @@ func newLocalConfig(dir string) (*LocalConfig, error) {
 		if err := c.setupDatasource(dir); err != nil {
 			return nil, err
 		}
+
+		// the config file marks the root, it must exist before anything is saved
+		if !Exists(c.datasource.Path) {
+			if err := c.datasource.Save(c.datasource.Get()); err != nil {
+				return nil, err
+			}
+		}
 		return c, nil
 	}
 
@@ -60,6 +90,13 @@ func newLocalConfig(dir string) (*LocalConfig, error) {
 		return nil, err
 	}
 
+	// write the config of a root detected without one so that it is detected directly next time
+	if !Exists(c.datasource.Path) {
+		if err := c.datasource.Save(c.datasource.Get()); err != nil {
+			return nil, err
+		}
+	}
+
 	return c, nil
 }
 
@@ -79,36 +116,48 @@ func (c *LocalConfig) setupDir(rootdir string) error {
 	return CreateDir(path)
 }
 
+// Returns the closest directory containing the .mynav directory of a root, empty if there is none.
+// The .mynav directories of topics and workspaces (holding layouts, templates...) are not roots.
 func (c *LocalConfig) detect() (string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		log.Panicln(err)
 	}
-	dirEntries := GetDirEntries(cwd)
+	return c.detectFrom(cwd), nil
+}
+
+// Returns the closest root containing dir, empty if there is none.
+func (c *LocalConfig) detectFrom(dir string) string {
 	homeDir, _ := os.UserHomeDir()
+	for ; dir != "/"; dir = filepath.Dir(dir) {
+		// the .mynav directory of the home directory is the global config
+		if dir == homeDir {
+			continue
+		}
 
-	for {
-		for _, entry := range dirEntries {
-			if cwd == "/" {
-				return "", nil
-			}
-			if entry.Name() == ".mynav" {
-				if cwd == homeDir {
-					break
-				}
-
-				if !entry.IsDir() {
-					os.Remove(filepath.Join(cwd, entry.Name()))
-					c.setupDir(cwd)
-					return cwd, nil
-				}
-
-				return cwd, nil
-			}
+		info, err := os.Stat(filepath.Join(dir, ".mynav"))
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			os.Remove(filepath.Join(dir, ".mynav"))
+			c.setupDir(dir)
+			return dir
+		}
+
+		if Exists(filepath.Join(dir, ".mynav", "config.json")) {
+			return dir
+		}
+
+		// roots created before the config file was always written only have an empty .mynav directory,
+		// so a .mynav directory without config is a root unless it belongs to a topic or a workspace of another root
+		if !isTopicDir(dir) && c.detectFrom(filepath.Dir(dir)) == "" {
+			return dir
 		}
-		cwd = filepath.Dir(cwd)
-		dirEntries = GetDirEntries(cwd)
 	}
+
+	return ""
 }
 
 func (g *LocalConfig) SetSelectedWorkspace(s string) {
@@ -117,6 +166,168 @@ func (g *LocalConfig) SetSelectedWorkspace(s string) {
 	g.datasource.Save(data)
 }
 
+func (g *LocalConfig) SetGitColumns(show bool) {
+	data := g.datasource.Get()
+	data.GitColumns = show
+	g.datasource.Save(data)
+}
+
+func (g *LocalConfig) RecordWorkspaceVisit(shortPath string, now time.Time) {
+	data := g.datasource.Get()
+	visits := copyMap(data.WorkspaceVisits)
+	visits[shortPath] = visits[shortPath].visit(now)
+	data.WorkspaceVisits = visits
+	g.datasource.Save(data)
+}
+
+func (g *LocalConfig) RecordSessionVisit(name string, now time.Time) {
+	data := g.datasource.Get()
+	visits := copyMap(data.SessionVisits)
+	visits[name] = visits[name].visit(now)
+	data.SessionVisits = visits
+	g.datasource.Save(data)
+}
+
+// Moves the visits, tags, pins and history of a workspace, or of all the workspaces of a topic, to a new short path.
+func (g *LocalConfig) MoveWorkspaceData(oldPath string, newPath string) {
+	data := g.datasource.Get()
+	data.WorkspaceVisits = moveKeys(data.WorkspaceVisits, oldPath, newPath)
+	data.Tags = moveKeys(data.Tags, oldPath, newPath)
+
+	pins := make(map[string]string, len(data.Pins))
+	for slot, shortPath := range data.Pins {
+		if shortPath == oldPath || strings.HasPrefix(shortPath, oldPath+"/") {
+			shortPath = newPath + strings.TrimPrefix(shortPath, oldPath)
+		}
+		pins[slot] = shortPath
+	}
+	data.Pins = pins
+
+	history := make([]*HistoryEntry, 0, len(data.History))
+	for _, e := range data.History {
+		if e.Workspace == oldPath || strings.HasPrefix(e.Workspace, oldPath+"/") {
+			e = &HistoryEntry{Workspace: newPath + strings.TrimPrefix(e.Workspace, oldPath)}
+		}
+		history = append(history, e)
+	}
+	data.History = history
+	g.datasource.Save(data)
+}
+
+// Removes the visits, tags, pins and history of a purged workspace, or of the workspaces of a purged topic.
+// The data of the workspaces that exist again under the path is kept.
+func (g *LocalConfig) RemoveWorkspaceData(path string, exists func(shortPath string) bool) {
+	stale := func(shortPath string) bool {
+		return (shortPath == path || strings.HasPrefix(shortPath, path+"/")) && !exists(shortPath)
+	}
+
+	data := g.datasource.Get()
+	data.WorkspaceVisits = removeKeys(data.WorkspaceVisits, stale)
+	data.Tags = removeKeys(data.Tags, stale)
+
+	pins := make(map[string]string, len(data.Pins))
+	for slot, shortPath := range data.Pins {
+		if !stale(shortPath) {
+			pins[slot] = shortPath
+		}
+	}
+	data.Pins = pins
+
+	// the current entry moves to the closest entry kept before it
+	history := make([]*HistoryEntry, 0, len(data.History))
+	position := 0
+	for i, e := range data.History {
+		if e.Workspace != "" && stale(e.Workspace) {
+			continue
+		}
+		if i <= data.HistoryPosition {
+			position = len(history)
+		}
+		history = append(history, e)
+	}
+	data.History = history
+	data.HistoryPosition = position
+	g.datasource.Save(data)
+}
+
+// Returns a copy of the map keyed by short path without the stale keys.
+func removeKeys[V any](m map[string]V, stale func(shortPath string) bool) map[string]V {
+	out := make(map[string]V, len(m))
+	for shortPath, v := range m {
+		if !stale(shortPath) {
+			out[shortPath] = v
+		}
+	}
+	return out
+}
+
+// Returns a copy of the map keyed by short path, with the keys under the old path moved to the new path.
+// Stale keys already under the new path (e.g. of a purged workspace) are replaced by the moved ones.
+func moveKeys[V any](m map[string]V, oldPath string, newPath string) map[string]V {
+	out := make(map[string]V, len(m))
+	for shortPath, v := range m {
+		switch {
+		case shortPath == oldPath || strings.HasPrefix(shortPath, oldPath+"/"):
+			shortPath = newPath + strings.TrimPrefix(shortPath, oldPath)
+		case shortPath == newPath || strings.HasPrefix(shortPath, newPath+"/"):
+			continue
+		}
+		out[shortPath] = v
+	}
+	return out
+}
+
+// Sets the tags of the workspace, removing the entry if there are none.
+func (g *LocalConfig) SetTags(shortPath string, tags []string) {
+	data := g.datasource.Get()
+	all := copyMap(data.Tags)
+	if len(tags) == 0 {
+		delete(all, shortPath)
+	} else {
+		all[shortPath] = tags
+	}
+	data.Tags = all
+	g.datasource.Save(data)
+}
+
+func (g *LocalConfig) SetSortMode(view string, mode SortMode) {
+	data := g.datasource.Get()
+	modes := copyMap(data.SortModes)
+	modes[view] = mode
+	data.SortModes = modes
+	g.datasource.Save(data)
+}
+
+// Pins the workspace to the slot, an empty short path empties it.
+func (g *LocalConfig) SetPin(slot int, shortPath string) {
+	data := g.datasource.Get()
+	pins := copyMap(data.Pins)
+	if shortPath == "" {
+		delete(pins, strconv.Itoa(slot))
+	} else {
+		pins[strconv.Itoa(slot)] = shortPath
+	}
+	data.Pins = pins
+	g.datasource.Save(data)
+}
+
+// Replaces the navigation history and the index of its current entry.
+func (g *LocalConfig) SetHistory(history []*HistoryEntry, position int) {
+	data := g.datasource.Get()
+	data.History = history
+	data.HistoryPosition = position
+	g.datasource.Save(data)
+}
+
+// Returns a copy of the map, which is replaced rather than modified as it may be read concurrently.
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m)+1)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
 func (l *LocalConfig) ConfigData() *LocalConfigData {
 	return l.datasource.Get()
 }
@@ -0,0 +1,440 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Version of the manifest format written by ExportManifest.
+const ManifestVersion = 1
+
+// Number of repos cloned at the same time when applying a manifest.
+const DefaultManifestJobs = 4
+
+// Manifest describes the topics and workspaces of a root, to recreate them on another machine.
+type Manifest struct {
+	Version int              `json:"version"`
+	Topics  []*ManifestTopic `json:"topics"`
+}
+
+// ManifestTopic describes a topic of a manifest.
+type ManifestTopic struct {
+	// full name of the topic (e.g. work/backend)
+	Name string `json:"name"`
+
+	// layout shared by the workspaces of the topic
+	Layout *Layout `json:"layout,omitempty"`
+
+	Workspaces []*ManifestWorkspace `json:"workspaces"`
+}
+
+// ManifestWorkspace describes a workspace of a manifest.
+type ManifestWorkspace struct {
+	Name string `json:"name"`
+
+	// origin of the git repository, the repo is cloned when the workspace is created
+	Remote string `json:"remote,omitempty"`
+
+	// branch checked out in the repository
+	Branch string `json:"branch,omitempty"`
+
+	Tags        []string `json:"tags,omitempty"`
+	Description string   `json:"description,omitempty"`
+
+	// layout of the workspace itself, not the one of its topic
+	Layout *Layout `json:"layout,omitempty"`
+
+	// directory the workspace links to, with the home directory as ~
+	Link string `json:"link,omitempty"`
+}
+
+// Kinds of drift between a manifest and the root.
+const (
+	// in the manifest but not in the root
+	DriftMissing = "missing"
+
+	// in the root but not in the manifest
+	DriftExtra = "extra"
+
+	// the repository has a different remote than in the manifest
+	DriftRemote = "remote"
+
+	// the repository is on a different branch than in the manifest
+	DriftBranch = "branch"
+)
+
+// Drift is a difference between a manifest and the root.
+type Drift struct {
+	// short path of the workspace, or name of the topic
+	ShortPath string
+
+	Kind   string
+	Detail string
+}
+
+func (d *Drift) String() string {
+	return fmt.Sprintf("%-8s %s: %s", d.Kind, d.ShortPath, d.Detail)
+}
+
+// ManifestResult is the outcome of creating a workspace of a manifest.
+type ManifestResult struct {
+	ShortPath string
+
+	// what was done (e.g. "clone git@github.com:owner/repo.git")
+	Action string
+
+	Err error
+}
+
+func (r *ManifestResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("failed %s: %s", r.ShortPath, r.Err)
+	}
+	return fmt.Sprintf("%s %s", r.Action, r.ShortPath)
+}
+
+// Returns the manifest of the topics and workspaces of the root, sorted by name.
+func (a *API) ExportManifest() *Manifest {
+	topics := slices.Clone(a.Topics())
+	slices.SortFunc(topics, func(t1, t2 *Topic) int {
+		return strings.Compare(t1.Name, t2.Name)
+	})
+
+	m := &Manifest{
+		Version: ManifestVersion,
+		Topics:  make([]*ManifestTopic, 0),
+	}
+	for _, t := range topics {
+		mt := &ManifestTopic{
+			Name:       t.Name,
+			Workspaces: make([]*ManifestWorkspace, 0),
+		}
+		mt.Layout, _ = t.Layout()
+
+		workspaces := slices.Clone(a.Workspaces(t))
+		slices.SortFunc(workspaces, func(w1, w2 *Workspace) int {
+			return strings.Compare(w1.Name, w2.Name)
+		})
+		for _, w := range workspaces {
+			mt.Workspaces = append(mt.Workspaces, a.manifestWorkspace(w))
+		}
+		m.Topics = append(m.Topics, mt)
+	}
+
+	return m
+}
+
+func (a *API) manifestWorkspace(w *Workspace) *ManifestWorkspace {
+	mw := &ManifestWorkspace{
+		Name:        w.Name,
+		Tags:        a.WorkspaceTags(w),
+		Description: w.Description(),
+	}
+	mw.Remote, _ = w.GitRemote()
+	mw.Branch = gitBranch(w)
+	mw.Layout, _ = loadLayout(filepath.Join(w.Path(), ".mynav", LayoutFile))
+
+	if w.Linked() {
+		mw.Link = HomeShortPath(w.RealPath())
+	}
+
+	return mw
+}
+
+// Reads the manifest at path.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d, expected %d", m.Version, ManifestVersion)
+	}
+
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	return m, nil
+}
+
+// Returns an error if a name of the manifest could create a topic or a workspace outside of the root.
+// Manifests are shared, so they are validated before anything is created.
+func (m *Manifest) validate() error {
+	for _, mt := range m.Topics {
+		if err := validateTopicName(mt.Name); err != nil {
+			return err
+		}
+
+		for _, mw := range mt.Workspaces {
+			if err := validateWorkspaceName(mw.Name); err != nil {
+				return fmt.Errorf("%w in topic %s", err, mt.Name)
+			}
+
+			if mw.Link != "" && !filepath.IsAbs(ExpandHome(mw.Link)) {
+				return fmt.Errorf("link of %s must be an absolute path", filepath.Join(mt.Name, mw.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Returns the differences between the manifest and the root.
+func (a *API) ManifestDrift(m *Manifest) []*Drift {
+	drift := make([]*Drift, 0)
+	topics := make(map[string]bool)
+	workspaces := make(map[string]bool)
+	for _, mt := range m.Topics {
+		topics[mt.Name] = true
+		t := a.Topic(mt.Name)
+		if t == nil && len(mt.Workspaces) == 0 {
+			drift = append(drift, &Drift{ShortPath: mt.Name, Kind: DriftMissing, Detail: "topic"})
+		}
+
+		for _, mw := range mt.Workspaces {
+			shortPath := filepath.Join(mt.Name, mw.Name)
+			workspaces[shortPath] = true
+
+			w := a.Workspace(shortPath)
+			if w == nil {
+				drift = append(drift, &Drift{ShortPath: shortPath, Kind: DriftMissing, Detail: mw.action()})
+				continue
+			}
+
+			remote, _ := w.GitRemote()
+			if mw.Remote != "" && !sameRemote(mw.Remote, remote) {
+				drift = append(drift, &Drift{
+					ShortPath: shortPath,
+					Kind:      DriftRemote,
+					Detail:    fmt.Sprintf("%s in the manifest, %s here", mw.Remote, valueOrNone(remote)),
+				})
+				continue
+			}
+
+			if branch := gitBranch(w); mw.Branch != "" && branch != mw.Branch {
+				drift = append(drift, &Drift{
+					ShortPath: shortPath,
+					Kind:      DriftBranch,
+					Detail:    fmt.Sprintf("%s in the manifest, %s here", mw.Branch, valueOrNone(branch)),
+				})
+			}
+		}
+	}
+
+	for _, t := range a.Topics() {
+		if !topics[t.Name] {
+			drift = append(drift, &Drift{ShortPath: t.Name, Kind: DriftExtra, Detail: "topic not in the manifest"})
+		}
+	}
+
+	for _, w := range a.AllWorkspaces() {
+		if topics[w.Topic.Name] && !workspaces[w.ShortPath()] {
+			drift = append(drift, &Drift{ShortPath: w.ShortPath(), Kind: DriftExtra, Detail: "not in the manifest"})
+		}
+	}
+
+	return drift
+}
+
+// Creates the topics and workspaces of the manifest missing from the root, existing ones are never modified.
+// Repos are cloned in parallel, at most jobs at a time. Each created or failed topic and workspace is reported
+// to onResult if not nil, one at a time, and returned.
+func (a *API) ApplyManifest(ctx context.Context, m *Manifest, jobs int, onResult func(*ManifestResult)) []*ManifestResult {
+	results := make([]*ManifestResult, 0)
+
+	// guards the results and the config, which is updated when a workspace is finished
+	var mu sync.Mutex
+	finish := func(w *Workspace, mw *ManifestWorkspace, external bool, result *ManifestResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if result.Err == nil && mw != nil {
+			result.Err = a.setupManifestWorkspace(w, mw, external)
+		}
+
+		results = append(results, result)
+		if onResult != nil {
+			onResult(result)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(jobs, 1))
+	for _, mt := range m.Topics {
+		t := a.Topic(mt.Name)
+		if t == nil {
+			var err error
+			t, err = a.createManifestTopic(mt)
+			finish(nil, nil, false, &ManifestResult{ShortPath: mt.Name, Action: "create topic", Err: err})
+			if err != nil {
+				continue
+			}
+		}
+
+		for _, mw := range mt.Workspaces {
+			shortPath := filepath.Join(mt.Name, mw.Name)
+			result := &ManifestResult{ShortPath: shortPath, Action: mw.action()}
+			if a.Workspace(shortPath) != nil {
+				continue
+			}
+
+			// a directory that is linked as it is belongs to the user, nothing is written into it
+			external := mw.Link != "" && Exists(ExpandHome(mw.Link))
+
+			// only the clones run in parallel, the workspaces are created in order
+			w, clone, err := a.createManifestWorkspace(t, mw)
+			if err != nil || clone == nil {
+				result.Err = err
+				finish(w, mw, external, result)
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				result.Err = clone(ctx)
+				finish(w, mw, external, result)
+			}()
+		}
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Creates the topic of the manifest with its layout.
+func (a *API) createManifestTopic(mt *ManifestTopic) (*Topic, error) {
+	t, err := a.NewTopic(mt.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if mt.Layout != nil {
+		if err := saveLayout(filepath.Join(t.Path(), ".mynav", LayoutFile), mt.Layout); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Creates the workspace of the manifest. Returns the clone to run if the repo has to be cloned, nil otherwise.
+// The workspace is removed if the clone fails.
+func (a *API) createManifestWorkspace(t *Topic, mw *ManifestWorkspace) (*Workspace, func(context.Context) error, error) {
+	opts := &CloneOptions{Branch: mw.Branch}
+	if mw.Link != "" {
+		target := ExpandHome(mw.Link)
+		if Exists(target) || mw.Remote == "" {
+			w, err := a.LinkWorkspace(t, target, mw.Name)
+			return w, nil, err
+		}
+
+		// the linked directory is cloned where it was and linked once the clone is done
+		if err := a.ValidateWorkspaceName(t, mw.Name); err != nil {
+			return nil, nil, err
+		}
+
+		w := newWorkspace(t, mw.Name)
+		return w, func(ctx context.Context) error {
+			if err := GitClone(ctx, mw.Remote, target, opts, nil); err != nil {
+				return err
+			}
+
+			_, err := a.LinkWorkspace(t, target, mw.Name)
+			return err
+		}, nil
+	}
+
+	w, err := a.fs.CreateWorkspace(t, mw.Name)
+	if err != nil || mw.Remote == "" {
+		return w, nil, err
+	}
+
+	return w, func(ctx context.Context) error {
+		if err := w.CloneRepo(ctx, mw.Remote, opts, nil); err != nil {
+			a.fs.RemoveWorkspace(w)
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// Sets the tags, description and layout of a workspace created from the manifest.
+// The layout is not written into an external directory, the tags and description are stored in the root.
+func (a *API) setupManifestWorkspace(w *Workspace, mw *ManifestWorkspace, external bool) error {
+	if len(mw.Tags) > 0 {
+		if err := a.SetWorkspaceTags(w, mw.Tags); err != nil {
+			return err
+		}
+	}
+
+	if mw.Description != "" {
+		if err := a.SetWorkspaceDescription(w, mw.Description); err != nil {
+			return err
+		}
+	}
+
+	// the cloned repo may have its own layout
+	path := filepath.Join(w.Path(), ".mynav", LayoutFile)
+	if mw.Layout != nil && !external && !Exists(path) {
+		return saveLayout(path, mw.Layout)
+	}
+
+	return nil
+}
+
+// Returns what creating the workspace does.
+func (mw *ManifestWorkspace) action() string {
+	switch {
+	case mw.Link != "" && (mw.Remote == "" || Exists(ExpandHome(mw.Link))):
+		return "link " + mw.Link
+	case mw.Remote != "":
+		return "clone " + mw.Remote
+	default:
+		return "create"
+	}
+}
+
+// Returns the branch checked out in the repository of the workspace, empty if it is not a repository.
+func gitBranch(w *Workspace) string {
+	if !Exists(filepath.Join(w.Path(), ".git")) {
+		return ""
+	}
+
+	status, err := GitStatusOf(w.Path())
+	if err != nil || status.Branch == "(detached)" {
+		return ""
+	}
+	return status.Branch
+}
+
+// Returns if the remotes point to the same repository, ignoring a trailing .git or /.
+func sameRemote(r1 string, r2 string) bool {
+	normalize := func(r string) string {
+		r = strings.TrimSuffix(strings.TrimSpace(r), "/")
+		return strings.TrimSuffix(r, ".git")
+	}
+	return normalize(r1) == normalize(r2)
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
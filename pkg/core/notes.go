@@ -0,0 +1,154 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Names of the description and notes files of a workspace.
+const (
+	DescriptionFile = "description"
+	NotesFile       = "notes.md"
+)
+
+// Directory of the .mynav directory of the root holding the files of the workspaces by short path.
+// The files are kept out of the workspaces, which are often git repositories.
+const WorkspaceFilesDir = "workspaces"
+
+// Returns the directory holding the description and notes of the workspace.
+func (w *Workspace) filesDir() string {
+	return workspaceFilesDir(w.Topic.basePath, w.ShortPath())
+}
+
+// Returns the path of the description file of the workspace.
+func (w *Workspace) DescriptionPath() string {
+	return filepath.Join(w.filesDir(), DescriptionFile)
+}
+
+// Returns the path of the markdown notes of the workspace.
+func (w *Workspace) NotesPath() string {
+	return filepath.Join(w.filesDir(), NotesFile)
+}
+
+func workspaceFilesDir(root string, shortPath string) string {
+	return filepath.Join(root, ".mynav", WorkspaceFilesDir, shortPath)
+}
+
+// Moves the files of a workspace, or of all the workspaces of a topic, to a new short path.
+// The files of a deleted workspace that was at the new short path are replaced.
+func moveWorkspaceFiles(root string, oldPath string, newPath string) error {
+	src := workspaceFilesDir(root, oldPath)
+	if !Exists(src) {
+		return nil
+	}
+
+	dest := workspaceFilesDir(root, newPath)
+	if err := os.RemoveAll(dest); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	return os.Rename(src, dest)
+}
+
+// Removes the files of a purged workspace, or of the workspaces of a purged topic.
+// The files of the workspaces that exist again under the path are kept.
+func removeWorkspaceFiles(root string, path string, exists func(shortPath string) bool) error {
+	dir := workspaceFilesDir(root, path)
+	if !Exists(dir) {
+		return nil
+	}
+
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+
+		shortPath := filepath.Join(path, strings.TrimPrefix(p, dir))
+		if exists(shortPath) {
+			return nil
+		}
+
+		if err := os.RemoveAll(p); err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	})
+}
+
+// Returns the description of the workspace on a single line, empty if there is none.
+func (w *Workspace) Description() string {
+	data, err := os.ReadFile(w.DescriptionPath())
+	if err != nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(string(data)), " ")
+}
+
+// Returns the notes of the workspace, empty if there are none.
+func (w *Workspace) Notes() string {
+	data, err := os.ReadFile(w.NotesPath())
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
+
+// Sets the description of the workspace, an empty description removes it.
+func (a *API) SetWorkspaceDescription(w *Workspace, description string) error {
+	path := w.DescriptionPath()
+	if strings.TrimSpace(description) == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(strings.TrimSpace(description)+"\n"), 0644)
+}
+
+// Opens the description of the workspace in the user's editor, blocks until the editor exits.
+func (a *API) EditWorkspaceDescription(w *Workspace) error {
+	return EditFile(w.DescriptionPath())
+}
+
+// Opens the notes of the workspace in the user's editor, blocks until the editor exits.
+func (a *API) EditWorkspaceNotes(w *Workspace) error {
+	return EditFile(w.NotesPath())
+}
+
+// Opens the file in the editor set by $VISUAL or $EDITOR (vi if neither is set), blocks until the editor exits.
+// The directory of the file is created if needed.
+func EditFile(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// the editor may be set with arguments (e.g. "code --wait")
+	command := strings.Fields(editor)
+	if !DoesProgramExist(command[0]) {
+		return errors.New(command[0] + " is not installed on the system, set $EDITOR to your editor")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return CommandWithRedirect(append(command, path)...).Run()
+}
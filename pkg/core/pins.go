@@ -0,0 +1,90 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Number of pin slots, numbered from 1.
+const PinSlots = 9
+
+// Returns the workspace pinned to the slot, nil if the slot is empty or the workspace no longer exists.
+func (a *API) PinnedWorkspace(slot int) *Workspace {
+	shortPath := a.local.ConfigData().Pins[strconv.Itoa(slot)]
+	if shortPath == "" {
+		return nil
+	}
+
+	return a.Workspace(shortPath)
+}
+
+// Returns the pinned workspaces by slot, nil for the empty slots.
+func (a *API) PinnedWorkspaces() []*Workspace {
+	out := make([]*Workspace, PinSlots)
+	for i := range out {
+		out[i] = a.PinnedWorkspace(i + 1)
+	}
+	return out
+}
+
+// Returns the slot the workspace is pinned to, 0 if it is not pinned.
+func (a *API) PinSlot(w *Workspace) int {
+	for key, shortPath := range a.local.ConfigData().Pins {
+		if shortPath == w.ShortPath() {
+			slot, _ := strconv.Atoi(key)
+			return slot
+		}
+	}
+	return 0
+}
+
+// Returns the first empty slot, 0 if all the slots are taken.
+func (a *API) FreePinSlot() int {
+	for slot := 1; slot <= PinSlots; slot++ {
+		if a.PinnedWorkspace(slot) == nil {
+			return slot
+		}
+	}
+	return 0
+}
+
+// Pins the workspace to the slot, replacing the workspace pinned there.
+// A workspace is pinned to a single slot, it is moved if it was pinned elsewhere.
+func (a *API) Pin(w *Workspace, slot int) error {
+	if err := validatePinSlot(slot); err != nil {
+		return err
+	}
+
+	if current := a.PinSlot(w); current != 0 {
+		a.local.SetPin(current, "")
+	}
+	a.local.SetPin(slot, w.ShortPath())
+	return nil
+}
+
+// Empties the slot.
+func (a *API) Unpin(slot int) error {
+	if err := validatePinSlot(slot); err != nil {
+		return err
+	}
+
+	a.local.SetPin(slot, "")
+	return nil
+}
+
+// Parses a slot number, returns an error if it is not between 1 and PinSlots.
+func ParsePinSlot(s string) (int, error) {
+	slot, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid slot %s, expected a number between 1 and %d", s, PinSlots)
+	}
+
+	return slot, validatePinSlot(slot)
+}
+
+func validatePinSlot(slot int) error {
+	if slot < 1 || slot > PinSlots {
+		return fmt.Errorf("invalid slot %d, expected a number between 1 and %d", slot, PinSlots)
+	}
+	return nil
+}
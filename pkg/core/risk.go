@@ -0,0 +1,165 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitRisk describes the work in a git repository that would be lost if it were deleted.
+type GitRisk struct {
+	// path of the repository relative to the mynav root (e.g. topic/workspace)
+	Repo string
+
+	// number of modified, staged or deleted files
+	Uncommitted int
+
+	// number of untracked files
+	Untracked int
+
+	// number of stash entries
+	Stashes int
+
+	// number of commits that are not on any remote
+	Unpushed int
+
+	// if the repository could not be inspected, its work is then assumed to be at risk
+	Failed bool
+}
+
+// Returns a summary of the risk (e.g. "topic/workspace: 2 uncommitted, 1 stash").
+func (r *GitRisk) String() string {
+	if r.Failed {
+		return r.Repo + ": could not be inspected"
+	}
+
+	parts := make([]string, 0)
+	add := func(n int, singular string, plural string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+singular)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, plural))
+		}
+	}
+	add(r.Uncommitted, "uncommitted change", "uncommitted changes")
+	add(r.Untracked, "untracked file", "untracked files")
+	add(r.Stashes, "stash", "stashes")
+	add(r.Unpushed, "unpushed commit", "unpushed commits")
+
+	return r.Repo + ": " + strings.Join(parts, ", ")
+}
+
+// Returns if the repository has any work at risk.
+func (r *GitRisk) any() bool {
+	return r.Failed || r.Uncommitted > 0 || r.Untracked > 0 || r.Stashes > 0 || r.Unpushed > 0
+}
+
+// Directories that are not searched for git repositories, as they are large and only hold generated or installed files.
+var riskSkippedDirs = map[string]bool{
+	"node_modules": true,
+	".venv":        true,
+	"venv":         true,
+	"__pycache__":  true,
+	".terraform":   true,
+	".gradle":      true,
+}
+
+// Inspects every git repository under path (including path itself) and returns the ones with work at risk.
+// A directory that cannot be read is returned as a failed risk, as it may hold repositories.
+// Repository paths are made relative to root.
+func GitRisks(root string, path string) ([]*GitRisk, error) {
+	repos := make([]string, 0)
+	risks := make([]*GitRisk, 0)
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			risk := &GitRisk{Failed: true}
+			risk.Repo, _ = filepath.Rel(root, p)
+			risks = append(risks, risk)
+			return nil
+		}
+
+		if d.IsDir() && p != path && riskSkippedDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+
+		if d.Name() == ".git" {
+			repos = append(repos, filepath.Dir(p))
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, repo := range repos {
+		risk, err := gitRiskOf(repo)
+		if err != nil {
+			risk = &GitRisk{Failed: true}
+		}
+
+		if !risk.any() {
+			continue
+		}
+
+		risk.Repo, _ = filepath.Rel(root, repo)
+		risks = append(risks, risk)
+	}
+
+	return risks, nil
+}
+
+// Inspects a single git repository.
+func gitRiskOf(repo string) (*GitRisk, error) {
+	risk := &GitRisk{}
+
+	status, err := gitLines(repo, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range status {
+		if strings.HasPrefix(line, "??") {
+			risk.Untracked++
+		} else {
+			risk.Uncommitted++
+		}
+	}
+
+	stashes, err := gitLines(repo, "stash", "list")
+	if err != nil {
+		return nil, err
+	}
+	risk.Stashes = len(stashes)
+
+	// commits of local branches that no remote branch contains, all of them if there is no remote
+	unpushed, err := gitLines(repo, "log", "--branches", "--not", "--remotes", "--format=%H")
+	if err != nil {
+		return nil, err
+	}
+	risk.Unpushed = len(unpushed)
+
+	return risk, nil
+}
+
+// Runs a git command in the repository and returns the non empty lines of the output.
+func gitLines(repo string, args ...string) ([]string, error) {
+	out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect git repository %s", repo)
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
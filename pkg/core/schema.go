@@ -0,0 +1,151 @@
+package core
+
+import (
+	"time"
+)
+
+// Version of the machine-readable schema.
+// Bump when a field is removed or its meaning changes, adding fields is not a breaking change.
+const SchemaVersion = 1
+
+// Machine-readable topic listing.
+type TopicsEnvelope struct {
+	Version int            `json:"version"`
+	Topics  []*TopicSchema `json:"topics"`
+}
+
+// Machine-readable workspace listing.
+type WorkspacesEnvelope struct {
+	Version    int                `json:"version"`
+	Workspaces []*WorkspaceSchema `json:"workspaces"`
+}
+
+// Machine-readable session listing.
+type SessionsEnvelope struct {
+	Version  int              `json:"version"`
+	Sessions []*SessionSchema `json:"sessions"`
+}
+
+// Machine-readable representation of a topic.
+type TopicSchema struct {
+	Name         string     `json:"name"`
+	Path         string     `json:"path"`
+	Workspaces   int        `json:"workspaces"`
+	LastModified *time.Time `json:"last_modified"`
+}
+
+// Machine-readable representation of a workspace.
+type WorkspaceSchema struct {
+	Name         string                  `json:"name"`
+	Topic        string                  `json:"topic"`
+	ShortPath    string                  `json:"short_path"`
+	Path         string                  `json:"path"`
+	Linked       bool                    `json:"linked"`
+	RealPath     string                  `json:"real_path"`
+	GitRemote    string                  `json:"git_remote"`
+	Tags         []string                `json:"tags"`
+	Description  string                  `json:"description"`
+	LastModified *time.Time              `json:"last_modified"`
+	Session      *WorkspaceSessionSchema `json:"session"`
+}
+
+// Session state of a workspace, nil if the workspace has no session.
+type WorkspaceSessionSchema struct {
+	Name     string `json:"name"`
+	Attached bool   `json:"attached"`
+}
+
+// Machine-readable representation of a session.
+type SessionSchema struct {
+	Name         string     `json:"name"`
+	Windows      int        `json:"windows"`
+	Panes        int        `json:"panes"`
+	Attached     bool       `json:"attached"`
+	Activity     *time.Time `json:"activity"`
+	Created      *time.Time `json:"created"`
+	LastAttached *time.Time `json:"last_attached"`
+	Workspace    *string    `json:"workspace"`
+}
+
+// Returns the listing of the topics.
+func (a *API) TopicsSchema(topics Topics) *TopicsEnvelope {
+	out := make([]*TopicSchema, 0)
+	for _, t := range topics {
+		out = append(out, &TopicSchema{
+			Name:         t.Name,
+			Path:         t.Path(),
+			Workspaces:   len(a.Workspaces(t)),
+			LastModified: schemaTime(t.LastModified()),
+		})
+	}
+	return &TopicsEnvelope{Version: SchemaVersion, Topics: out}
+}
+
+// Returns the listing of the workspaces.
+func (a *API) WorkspacesSchema(workspaces Workspaces) *WorkspacesEnvelope {
+	sMap := a.SessionMap()
+	out := make([]*WorkspaceSchema, 0)
+	for _, w := range workspaces {
+		remote, _ := w.GitRemote()
+		tags := a.WorkspaceTags(w)
+		if tags == nil {
+			tags = []string{}
+		}
+
+		ws := &WorkspaceSchema{
+			Name:         w.Name,
+			Topic:        w.Topic.Name,
+			ShortPath:    w.ShortPath(),
+			Path:         w.Path(),
+			Linked:       w.Linked(),
+			RealPath:     w.RealPath(),
+			GitRemote:    remote,
+			Tags:         tags,
+			Description:  w.Description(),
+			LastModified: schemaTime(w.LastModified()),
+		}
+
+		if s := sMap.Get(w); s != nil {
+			ws.Session = &WorkspaceSessionSchema{
+				Name:     s.Name,
+				Attached: s.Attached > 0,
+			}
+		}
+		out = append(out, ws)
+	}
+	return &WorkspacesEnvelope{Version: SchemaVersion, Workspaces: out}
+}
+
+// Returns the listing of the sessions.
+func (a *API) SessionsSchema(sessions []*Session) *SessionsEnvelope {
+	out := make([]*SessionSchema, 0)
+	for _, s := range sessions {
+		panes, _ := s.ListPanes()
+		ss := &SessionSchema{
+			Name:         s.Name,
+			Windows:      s.Windows,
+			Panes:        len(panes),
+			Attached:     s.Attached > 0,
+			Activity:     schemaTime(UnixTime(s.Activity)),
+			Created:      schemaTime(UnixTime(s.Created)),
+			LastAttached: schemaTime(UnixTime(s.LastAttached)),
+		}
+
+		if s.Workspace != nil {
+			shortPath := s.Workspace.ShortPath()
+			ss.Workspace = &shortPath
+		}
+		out = append(out, ss)
+	}
+	return &SessionsEnvelope{Version: SchemaVersion, Sessions: out}
+}
+
+// Returns nil for unset times so that they serialize to null.
+func schemaTime(t time.Time) *time.Time {
+	if t.IsZero() || t.Unix() == 0 {
+		return nil
+	}
+
+	t = t.UTC()
+	return &t
+}
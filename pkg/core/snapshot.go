@@ -0,0 +1,279 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Data for the session snapshot store.
+type SnapshotData struct {
+	Sessions []*SessionSnapshot `json:"sessions"`
+}
+
+// SessionSnapshot captures the windows and panes of a workspace session so that it can be rebuilt.
+type SessionSnapshot struct {
+	// short path of the workspace the session belongs to
+	Workspace string `json:"workspace"`
+
+	// captured windows and panes
+	Layout *Layout `json:"layout"`
+
+	// time of the capture
+	SavedAt time.Time `json:"saved-at"`
+}
+
+// Snapshots exposes crud on the session snapshot store (.mynav/sessions.json).
+type Snapshots struct {
+	datasource *Datasource[SnapshotData]
+}
+
+func newSnapshots(rootdir string) (*Snapshots, error) {
+	ds, err := newDatasource(filepath.Join(rootdir, ".mynav", "sessions.json"), &SnapshotData{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshots{datasource: ds}, nil
+}
+
+// Returns all the snapshots.
+func (s *Snapshots) All() []*SessionSnapshot {
+	return s.datasource.Get().Sessions
+}
+
+// Replaces all the snapshots.
+func (s *Snapshots) Set(snapshots []*SessionSnapshot) error {
+	data := s.datasource.Get()
+	data.Sessions = snapshots
+	return s.datasource.Save(data)
+}
+
+// Removes the snapshot of the workspace.
+func (s *Snapshots) Remove(shortPath string) error {
+	out := make([]*SessionSnapshot, 0)
+	for _, snapshot := range s.All() {
+		if snapshot.Workspace != shortPath {
+			out = append(out, snapshot)
+		}
+	}
+
+	return s.Set(out)
+}
+
+// Moves the snapshots of a workspace, or of all the workspaces of a topic, to a new short path.
+// Stale snapshots already at the new path are replaced.
+func (s *Snapshots) Move(oldPath string, newPath string) error {
+	out := make([]*SessionSnapshot, 0)
+	for _, snapshot := range s.All() {
+		switch {
+		case snapshot.Workspace == oldPath || strings.HasPrefix(snapshot.Workspace, oldPath+"/"):
+			moved := *snapshot
+			moved.Workspace = newPath + strings.TrimPrefix(snapshot.Workspace, oldPath)
+			snapshot = &moved
+		case snapshot.Workspace == newPath || strings.HasPrefix(snapshot.Workspace, newPath+"/"):
+			continue
+		}
+		out = append(out, snapshot)
+	}
+
+	return s.Set(out)
+}
+
+// Captures the windows, panes, directories, layouts and running commands of a session.
+func captureSession(session *Session) (*SessionSnapshot, error) {
+	windows, err := session.ListWindows()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Index < windows[j].Index
+	})
+
+	layout := &Layout{
+		Windows: make([]*LayoutWindow, 0),
+	}
+	for _, w := range windows {
+		panes, err := w.ListPanes()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(panes) == 0 {
+			continue
+		}
+		sort.Slice(panes, func(i, j int) bool {
+			return panes[i].Index < panes[j].Index
+		})
+
+		lw := &LayoutWindow{
+			Name:    w.Name,
+			Dir:     panes[0].CurrentPath,
+			Command: paneCommand(panes[0]),
+			Layout:  w.Layout,
+			Panes:   make([]*LayoutPane, 0),
+		}
+		for _, p := range panes[1:] {
+			lw.Panes = append(lw.Panes, &LayoutPane{
+				Dir:     p.CurrentPath,
+				Command: paneCommand(p),
+			})
+		}
+		layout.Windows = append(layout.Windows, lw)
+	}
+
+	return &SessionSnapshot{
+		Workspace: session.Workspace.ShortPath(),
+		Layout:    layout,
+		SavedAt:   time.Now(),
+	}, nil
+}
+
+// Programs that are safe to run again without their arguments when a session is restored.
+// tmux only reports the name of the running program, so rerunning others (e.g. make, ssh or rm) would be wrong or unsafe.
+var restorableCommands = map[string]bool{
+	"vim":        true,
+	"nvim":       true,
+	"vi":         true,
+	"nano":       true,
+	"micro":      true,
+	"emacs":      true,
+	"hx":         true,
+	"kak":        true,
+	"top":        true,
+	"htop":       true,
+	"btop":       true,
+	"lazygit":    true,
+	"lazydocker": true,
+	"tig":        true,
+	"k9s":        true,
+	"ranger":     true,
+	"lf":         true,
+	"nnn":        true,
+	"yazi":       true,
+}
+
+// Returns the command running in the pane if it can be restored, empty otherwise (e.g. for a shell).
+func paneCommand(p *gotmux.Pane) string {
+	if !restorableCommands[p.CurrentCommand] {
+		return ""
+	}
+
+	return p.CurrentCommand
+}
+
+// Returns a copy of the captured layout without the commands that cannot be restored.
+func (l *Layout) restorable() *Layout {
+	out := &Layout{Windows: make([]*LayoutWindow, 0, len(l.Windows))}
+	for _, lw := range l.Windows {
+		window := *lw
+		if !restorableCommands[window.Command] {
+			window.Command = ""
+		}
+
+		window.Panes = make([]*LayoutPane, 0, len(lw.Panes))
+		for _, lp := range lw.Panes {
+			pane := *lp
+			if !restorableCommands[pane.Command] {
+				pane.Command = ""
+			}
+			window.Panes = append(window.Panes, &pane)
+		}
+		out.Windows = append(out.Windows, &window)
+	}
+	return out
+}
+
+// Captures all the running workspace sessions.
+func (a *API) captureSessions() ([]*SessionSnapshot, error) {
+	snapshots := make([]*SessionSnapshot, 0)
+	for _, s := range a.AllSessions() {
+		if s.Workspace == nil {
+			continue
+		}
+
+		snapshot, err := captureSession(s)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(snapshot.Layout.Windows) > 0 {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// Saves a snapshot of all the running workspace sessions, keeping the snapshots of sessions that are not running.
+// This ensures that saving before restoring (e.g. after a reboot) does not lose the previous snapshot.
+// Returns the number of sessions saved.
+func (a *API) SaveSessions() (int, error) {
+	snapshots, err := a.captureSessions()
+	if err != nil {
+		return 0, err
+	}
+	count := len(snapshots)
+
+	captured := map[string]bool{}
+	for _, s := range snapshots {
+		captured[s.Workspace] = true
+	}
+
+	for _, s := range a.snapshots.All() {
+		if !captured[s.Workspace] && a.Workspace(s.Workspace) != nil {
+			snapshots = append(snapshots, s)
+		}
+	}
+
+	return count, a.snapshots.Set(snapshots)
+}
+
+// Saves the sessions when mynav exits, see SaveSessions.
+func (a *API) AutoSaveSessions() error {
+	_, err := a.SaveSessions()
+	return err
+}
+
+// Returns the saved session snapshots.
+func (a *API) SessionSnapshots() []*SessionSnapshot {
+	return a.snapshots.All()
+}
+
+// Rebuilds the saved sessions that are not running.
+// Snapshots of workspaces that no longer exist are skipped.
+// Returns the number of sessions restored.
+func (a *API) RestoreSessions() (int, error) {
+	sMap := a.SessionMap()
+	count := 0
+	for _, snapshot := range a.snapshots.All() {
+		w := a.Workspace(snapshot.Workspace)
+		if w == nil || sMap.Get(w) != nil {
+			continue
+		}
+
+		if snapshot.Layout == nil || len(snapshot.Layout.Windows) == 0 {
+			continue
+		}
+		layout := snapshot.Layout.restorable()
+
+		session, err := a.tmux.NewSession(&gotmux.SessionOptions{
+			Name:           w.TmuxName(),
+			StartDirectory: layout.startDir(w.RealPath()),
+		})
+		if err != nil {
+			return count, err
+		}
+
+		if err := a.applyLayout(session, w.RealPath(), layout); err != nil {
+			session.Kill()
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Returns the tags of the workspace, sorted.
+func (a *API) WorkspaceTags(w *Workspace) []string {
+	return a.local.ConfigData().Tags[w.ShortPath()]
+}
+
+// Replaces the tags of the workspace, no tags removes them all.
+// Tags are lowercased, duplicates are dropped.
+func (a *API) SetWorkspaceTags(w *Workspace, tags []string) error {
+	normalized := make([]string, 0)
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+
+		if err := validateTag(tag); err != nil {
+			return err
+		}
+
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+
+	a.local.SetTags(w.ShortPath(), normalized)
+	return nil
+}
+
+// Adds tags to the workspace.
+func (a *API) AddWorkspaceTags(w *Workspace, tags ...string) error {
+	return a.SetWorkspaceTags(w, append(slices.Clone(a.WorkspaceTags(w)), tags...))
+}
+
+// Removes tags from the workspace.
+// Tags are matched regardless of case, as they are stored lowercased.
+func (a *API) RemoveWorkspaceTags(w *Workspace, tags ...string) error {
+	removed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		removed = append(removed, normalizeTag(tag))
+	}
+
+	remaining := make([]string, 0)
+	for _, tag := range a.WorkspaceTags(w) {
+		if !slices.Contains(removed, tag) {
+			remaining = append(remaining, tag)
+		}
+	}
+	return a.SetWorkspaceTags(w, remaining)
+}
+
+// Returns the tag as it is stored.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Returns every tag in use, sorted.
+func (a *API) AllTags() []string {
+	out := make([]string, 0)
+	for _, tags := range a.local.ConfigData().Tags {
+		for _, tag := range tags {
+			if !slices.Contains(out, tag) {
+				out = append(out, tag)
+			}
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func validateTag(tag string) error {
+	for _, r := range tag {
+		if unicode.IsSpace(r) || r == ',' || r == ':' {
+			return fmt.Errorf("invalid tag %s, tags cannot contain spaces, ',' or ':'", tag)
+		}
+	}
+	return nil
+}
+
+// Splits tags separated by commas or spaces.
+func ParseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// WorkspaceQuery filters workspaces by tag and topic, e.g. "tag:go topic:infra api".
+type WorkspaceQuery struct {
+	// every tag must be on the workspace
+	Tags []string
+
+	// name of the topic of the workspace or of one of its parent topics, any topic if empty
+	Topic string
+
+	// the rest of the query, with the filters removed
+	Text string
+}
+
+// Parses a query made of "tag:" and "topic:" filters and free text.
+func ParseWorkspaceQuery(s string) *WorkspaceQuery {
+	q := &WorkspaceQuery{Tags: make([]string, 0)}
+	text := make([]string, 0)
+	for _, field := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(field, "tag:") && len(field) > len("tag:"):
+			q.Tags = append(q.Tags, strings.ToLower(strings.TrimPrefix(field, "tag:")))
+		case strings.HasPrefix(field, "topic:") && len(field) > len("topic:"):
+			q.Topic = strings.TrimPrefix(field, "topic:")
+		default:
+			text = append(text, field)
+		}
+	}
+	q.Text = strings.Join(text, " ")
+	return q
+}
+
+// Returns if the query has tag or topic filters.
+func (q *WorkspaceQuery) Filtered() bool {
+	return len(q.Tags) > 0 || q.Topic != ""
+}
+
+// Returns if the workspace passes the tag and topic filters of the query, the text is not matched.
+func (q *WorkspaceQuery) Matches(w *Workspace, tags []string) bool {
+	if q.Topic != "" && w.Topic.Name != q.Topic && !strings.HasPrefix(w.Topic.Name, q.Topic+"/") {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
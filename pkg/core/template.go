@@ -0,0 +1,267 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Directory of the templates, in the global ~/.mynav directory and in the .mynav directory of a topic.
+const TemplatesDir = "templates"
+
+// Optional file of a template describing it and listing its post-create commands, it is not copied.
+const TemplateConfigFile = ".mynav-template.json"
+
+// Files with this suffix are rendered with the template variables, the suffix is dropped.
+// Other files are copied as they are.
+const TemplateSuffix = ".tmpl"
+
+// Template is a directory of files scaffolded into new workspaces.
+type Template struct {
+	Name string
+
+	// directory of the template
+	Path string
+
+	// topic the template belongs to, empty for a global template
+	Topic string
+
+	TemplateConfig
+}
+
+// TemplateConfig is the content of the config file of a template.
+type TemplateConfig struct {
+	Description string `json:"description,omitempty"`
+
+	// shell commands run in the workspace once the files are rendered (e.g. "git init").
+	// They are not rendered, the variables are passed as environment variables (see TemplateVars.env).
+	Commands []string `json:"commands,omitempty"`
+}
+
+// TemplateVars are the variables available to the files and file names of a template, and to its commands as environment variables.
+type TemplateVars struct {
+	// name of the workspace
+	Name string
+
+	// full name of the topic
+	Topic string
+
+	// absolute path of the workspace
+	Path string
+
+	// date of the creation (e.g. 2024-06-30)
+	Date string
+}
+
+// Returns the templates available to the workspaces of the topic, sorted by name.
+// Templates of the topic take precedence over the templates of its parents, which take precedence over global templates.
+func (a *API) Templates(t *Topic) []*Template {
+	// from the lowest to the highest precedence
+	topics := make([]string, 0)
+	for name := t.Name; name != "." && name != ""; name = filepath.Dir(name) {
+		topics = append([]string{name}, topics...)
+	}
+
+	byName := make(map[string]*Template)
+	for _, topic := range append([]string{""}, topics...) {
+		dir := filepath.Join(a.global.dirPath(), TemplatesDir)
+		if topic != "" {
+			dir = filepath.Join(a.fs.path, topic, ".mynav", TemplatesDir)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				byName[entry.Name()] = &Template{
+					Name:  entry.Name(),
+					Path:  filepath.Join(dir, entry.Name()),
+					Topic: topic,
+				}
+			}
+		}
+	}
+
+	out := make([]*Template, 0)
+	for _, tmpl := range byName {
+		// a template with an invalid config fails when it is used
+		tmpl.TemplateConfig, _ = loadTemplateConfig(tmpl.Path)
+		out = append(out, tmpl)
+	}
+	slices.SortFunc(out, func(t1, t2 *Template) int {
+		return strings.Compare(t1.Name, t2.Name)
+	})
+
+	return out
+}
+
+// Returns the template available to the topic by name, an error if there is none or if its config is invalid.
+func (a *API) Template(t *Topic, name string) (*Template, error) {
+	for _, tmpl := range a.Templates(t) {
+		if tmpl.Name != name {
+			continue
+		}
+
+		if _, err := loadTemplateConfig(tmpl.Path); err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+
+	return nil, fmt.Errorf("template %s does not exist", name)
+}
+
+// Creates a new workspace with the files of the template and runs its commands.
+// If the template cannot be rendered or a command fails, the workspace is removed.
+func (a *API) NewWorkspaceFromTemplate(t *Topic, name string, tmpl *Template) (*Workspace, error) {
+	w, err := a.NewWorkspace(t, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.apply(w); err != nil {
+		// roll back the workspace that was created for the template
+		a.SelectWorkspace(nil)
+		a.fs.RemoveWorkspace(w)
+		return nil, fmt.Errorf("template %s: %w", tmpl.Name, err)
+	}
+
+	return w, nil
+}
+
+// Returns the name of the template with the topic it belongs to (e.g. "go (work)").
+func (tmpl *Template) String() string {
+	if tmpl.Topic == "" {
+		return tmpl.Name
+	}
+	return fmt.Sprintf("%s (%s)", tmpl.Name, tmpl.Topic)
+}
+
+// Renders the files of the template into the workspace, then runs its commands.
+func (tmpl *Template) apply(w *Workspace) error {
+	vars := &TemplateVars{
+		Name:  w.Name,
+		Topic: w.Topic.Name,
+		Path:  w.Path(),
+		Date:  time.Now().Format(time.DateOnly),
+	}
+
+	err := filepath.WalkDir(tmpl.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(tmpl.Path, path)
+		if err != nil || rel == "." || rel == TemplateConfigFile {
+			return err
+		}
+
+		// file names can use the variables too (e.g. cmd/{{.Name}}/main.go)
+		rel, err = renderTemplate(rel, rel, vars)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(w.Path(), strings.TrimSuffix(rel, TemplateSuffix))
+
+		if d.IsDir() {
+			return os.MkdirAll(dest, 0755)
+		}
+
+		return renderTemplateFile(path, dest, vars)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, command := range tmpl.Commands {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = w.Path()
+		cmd.Env = append(os.Environ(), vars.env()...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			// the output of the command explains the failure better than its exit status
+			if message := strings.TrimSpace(string(out)); message != "" {
+				return fmt.Errorf("%s failed: %s", command, message)
+			}
+			return fmt.Errorf("%s failed: %w", command, err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the variables as environment variables of the commands (e.g. MYNAV_NAME).
+// Passing them in the environment rather than in the command keeps names with spaces or quotes from breaking it.
+func (v *TemplateVars) env() []string {
+	return []string{
+		"MYNAV_NAME=" + v.Name,
+		"MYNAV_TOPIC=" + v.Topic,
+		"MYNAV_PATH=" + v.Path,
+		"MYNAV_DATE=" + v.Date,
+	}
+}
+
+// Copies the file of the template to dest, rendering it if it has the template suffix.
+func renderTemplateFile(path string, dest string, vars *TemplateVars) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasSuffix(path, TemplateSuffix) {
+		rendered, err := renderTemplate(filepath.Base(path), string(data), vars)
+		if err != nil {
+			return err
+		}
+		data = []byte(rendered)
+	}
+
+	return os.WriteFile(dest, data, info.Mode().Perm())
+}
+
+func renderTemplate(name string, text string, vars *TemplateVars) (string, error) {
+	t, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, vars); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Loads the config of the template in dir, empty if it has none.
+func loadTemplateConfig(dir string) (TemplateConfig, error) {
+	path := filepath.Join(dir, TemplateConfigFile)
+	if !Exists(path) {
+		return TemplateConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TemplateConfig{}, err
+	}
+
+	var config TemplateConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return TemplateConfig{}, fmt.Errorf("invalid template config %s: %w", path, err)
+	}
+	return config, nil
+}
@@ -4,9 +4,15 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
+// Name of the file marking a directory inside a topic as a sub-topic rather than a workspace.
+const TopicMarker = ".mynav-topic"
+
+// Topic groups workspaces and sub-topics.
+// The name is the path of the topic relative to the root (e.g. "org/team" for a sub-topic).
 type Topic struct {
 	basePath string
 	Name     string
@@ -23,6 +29,30 @@ func (t *Topic) Path() string {
 	return filepath.Join(t.basePath, t.Name)
 }
 
+// Returns the name of the parent topic, empty for a top level topic.
+func (t *Topic) Parent() string {
+	parent := filepath.Dir(t.Name)
+	if parent == "." {
+		return ""
+	}
+	return parent
+}
+
+// Returns the last element of the name.
+func (t *Topic) BaseName() string {
+	return filepath.Base(t.Name)
+}
+
+// Returns how deep the topic is nested, 0 for a top level topic.
+func (t *Topic) Depth() int {
+	return strings.Count(t.Name, "/")
+}
+
+// Returns if the topic with the given name is this topic or one of its sub-topics at any depth.
+func (t *Topic) Contains(name string) bool {
+	return name == t.Name || strings.HasPrefix(name, t.Name+"/")
+}
+
 func (t *Topic) LastModified() time.Time {
 	fi, err := os.Stat(t.Path())
 	if err != nil {
@@ -31,6 +61,11 @@ func (t *Topic) LastModified() time.Time {
 	return fi.ModTime()
 }
 
+// Returns the session layout shared by the workspaces of this topic, nil if there is none.
+func (t *Topic) Layout() (*Layout, error) {
+	return loadLayout(filepath.Join(t.Path(), ".mynav", LayoutFile))
+}
+
 type Topics []*Topic
 
 func (t Topics) Sorted() Topics {
@@ -39,3 +74,33 @@ func (t Topics) Sorted() Topics {
 	})
 	return t
 }
+
+// Reorders the topics so that each topic is directly followed by its sub-topics.
+// The relative order of sibling topics is kept.
+func (t Topics) Tree() Topics {
+	names := make(map[string]bool, len(t))
+	for _, topic := range t {
+		names[topic.Name] = true
+	}
+
+	children := make(map[string]Topics)
+	roots := make(Topics, 0)
+	for _, topic := range t {
+		if parent := topic.Parent(); names[parent] {
+			children[parent] = append(children[parent], topic)
+		} else {
+			roots = append(roots, topic)
+		}
+	}
+
+	out := make(Topics, 0, len(t))
+	var walk func(topics Topics)
+	walk = func(topics Topics) {
+		for _, topic := range topics {
+			out = append(out, topic)
+			walk(children[topic.Name])
+		}
+	}
+	walk(roots)
+	return out
+}
@@ -0,0 +1,237 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Kinds of trash items.
+const (
+	TrashTopic     = "topic"
+	TrashWorkspace = "workspace"
+)
+
+// Default number of days deleted items are kept in the trash.
+const DefaultTrashRetentionDays = 30
+
+// Data for the trash store.
+type TrashData struct {
+	Items []*TrashItem `json:"items"`
+}
+
+// TrashItem is a deleted topic or workspace kept in the trash.
+type TrashItem struct {
+	// name of the directory holding the item in the trash
+	Id string `json:"id"`
+
+	// kind of the item, TrashTopic or TrashWorkspace
+	Kind string `json:"kind"`
+
+	// path the item was deleted from, relative to the mynav root (topic or topic/workspace)
+	Origin string `json:"origin"`
+
+	// time of the deletion
+	DeletedAt time.Time `json:"deleted-at"`
+}
+
+// Trash exposes crud on the trash (.mynav/trash) and its store (.mynav/trash.json).
+type Trash struct {
+	datasource *Datasource[TrashData]
+
+	// mynav root
+	root string
+
+	// directory holding the deleted items
+	dir string
+}
+
+func newTrash(rootdir string) (*Trash, error) {
+	ds, err := newDatasource(filepath.Join(rootdir, ".mynav", "trash.json"), &TrashData{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Trash{
+		datasource: ds,
+		root:       rootdir,
+		dir:        filepath.Join(rootdir, ".mynav", "trash"),
+	}, nil
+}
+
+// Returns the items in the trash, most recently deleted first.
+func (t *Trash) Items() []*TrashItem {
+	items := append([]*TrashItem{}, t.datasource.Get().Items...)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items
+}
+
+// Returns the item with the id, nil if not found.
+func (t *Trash) Item(id string) *TrashItem {
+	for _, item := range t.datasource.Get().Items {
+		if item.Id == id {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// Returns the path of the item in the trash.
+func (t *Trash) path(item *TrashItem) string {
+	return filepath.Join(t.dir, item.Id)
+}
+
+// Returns the path the item was deleted from.
+func (t *Trash) OriginPath(item *TrashItem) string {
+	return filepath.Join(t.root, item.Origin)
+}
+
+// Moves the directory at path into the trash.
+func (t *Trash) Put(kind string, path string) (*TrashItem, error) {
+	origin, err := filepath.Rel(t.root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(t.dir, 0755); err != nil {
+		return nil, err
+	}
+
+	item := &TrashItem{
+		Id:        strconv.FormatInt(time.Now().UnixNano(), 10),
+		Kind:      kind,
+		Origin:    origin,
+		DeletedAt: time.Now(),
+	}
+	if err := os.Rename(path, t.path(item)); err != nil {
+		return nil, err
+	}
+
+	data := t.datasource.Get()
+	data.Items = append(data.Items, item)
+	return item, t.datasource.Save(data)
+}
+
+// Moves the item back to where it was deleted from.
+func (t *Trash) Restore(item *TrashItem) error {
+	origin := t.OriginPath(item)
+	if Exists(origin) {
+		return fmt.Errorf("cannot restore %s, it already exists", item.Origin)
+	}
+
+	if !Exists(t.path(item)) {
+		t.remove(item)
+		return fmt.Errorf("%s is no longer in the trash", item.Origin)
+	}
+
+	// the topic of a workspace may have been deleted since
+	if err := os.MkdirAll(filepath.Dir(origin), 0755); err != nil {
+		return err
+	}
+
+	if err := os.Rename(t.path(item), origin); err != nil {
+		return err
+	}
+
+	return t.remove(item)
+}
+
+// Permanently deletes the item.
+func (t *Trash) Purge(item *TrashItem) error {
+	if err := os.RemoveAll(t.path(item)); err != nil {
+		return err
+	}
+
+	return t.remove(item)
+}
+
+// Removes the item from the store.
+func (t *Trash) remove(item *TrashItem) error {
+	data := t.datasource.Get()
+	items := make([]*TrashItem, 0)
+	for _, other := range data.Items {
+		if other.Id != item.Id {
+			items = append(items, other)
+		}
+	}
+
+	if len(items) == len(data.Items) {
+		return errors.New("item is not in the trash")
+	}
+
+	data.Items = items
+	return t.datasource.Save(data)
+}
+
+// Returns the items in the trash, most recently deleted first.
+func (a *API) TrashItems() []*TrashItem {
+	return a.trash.Items()
+}
+
+// Returns the item in the trash with the id, nil if not found.
+func (a *API) TrashItem(id string) *TrashItem {
+	return a.trash.Item(id)
+}
+
+// Moves the item back to where it was deleted from.
+func (a *API) RestoreTrashItem(item *TrashItem) error {
+	return a.trash.Restore(item)
+}
+
+// Permanently deletes the item, with the data (tags, visits...) and the files (description, notes) of its workspaces.
+// The data is kept while the item is in the trash so that it is back when the item is restored.
+func (a *API) PurgeTrashItem(item *TrashItem) error {
+	if err := a.trash.Purge(item); err != nil {
+		return err
+	}
+
+	// a workspace may have been created at the same path since the deletion
+	exists := func(shortPath string) bool {
+		return Exists(filepath.Join(a.fs.path, shortPath))
+	}
+	a.local.RemoveWorkspaceData(item.Origin, exists)
+	return removeWorkspaceFiles(a.fs.path, item.Origin, exists)
+}
+
+// Permanently deletes all the items in the trash, returns the number of items purged.
+func (a *API) EmptyTrash() (int, error) {
+	return a.purgeTrashOlderThan(0)
+}
+
+// Permanently deletes the items deleted before the age, returns the number of items purged.
+func (a *API) purgeTrashOlderThan(age time.Duration) (int, error) {
+	count := 0
+	for _, item := range a.trash.Items() {
+		if time.Since(item.DeletedAt) < age {
+			continue
+		}
+
+		if err := a.PurgeTrashItem(item); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Returns how long deleted items are kept in the trash, 0 if they are kept forever.
+// Configured in days with trash-retention-days in the local config, negative to keep forever.
+func (a *API) TrashRetention() time.Duration {
+	days := a.local.ConfigData().TrashRetentionDays
+	switch {
+	case days < 0:
+		return 0
+	case days == 0:
+		days = DefaultTrashRetentionDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"errors"
+	"sync"
+)
+
+// Max number of operations that can be undone.
+const undoLimit = 50
+
+// operation is a destructive operation performed through the api that can be reverted.
+type operation struct {
+	// shown once the operation is undone (e.g. "delete workspace topic/workspace")
+	description string
+
+	// reverts the operation
+	undo func() error
+}
+
+// undoStack holds the operations performed during the lifetime of the api, most recent last.
+type undoStack struct {
+	operations []*operation
+	mu         sync.Mutex
+}
+
+func newUndoStack() *undoStack {
+	return &undoStack{
+		operations: make([]*operation, 0),
+	}
+}
+
+// Records an operation, dropping the oldest one past the limit.
+func (u *undoStack) push(description string, undo func() error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.operations = append(u.operations, &operation{
+		description: description,
+		undo:        undo,
+	})
+
+	if len(u.operations) > undoLimit {
+		u.operations = u.operations[1:]
+	}
+}
+
+// Removes and returns the most recent operation, nil if there is none.
+func (u *undoStack) pop() *operation {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.operations) == 0 {
+		return nil
+	}
+
+	op := u.operations[len(u.operations)-1]
+	u.operations = u.operations[:len(u.operations)-1]
+	return op
+}
+
+// Reverts the last delete, rename or move and returns its description.
+func (a *API) Undo() (string, error) {
+	op := a.undo.pop()
+	if op == nil {
+		return "", errors.New("nothing to undo")
+	}
+
+	if err := op.undo(); err != nil {
+		return "", err
+	}
+
+	return op.description, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/GianlucaP106/gotmux/gotmux"
+)
+
+// Returns the windows of the session ordered by index.
+func (a *API) SessionWindows(s *Session) ([]*gotmux.Window, error) {
+	windows, err := s.ListWindows()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Index < windows[j].Index
+	})
+	return windows, nil
+}
+
+// Returns the panes of the window ordered by index.
+func (a *API) WindowPanes(w *gotmux.Window) ([]*gotmux.Pane, error) {
+	panes, err := w.ListPanes()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(panes, func(i, j int) bool {
+		return panes[i].Index < panes[j].Index
+	})
+	return panes, nil
+}
+
+// Returns the active pane of the window.
+func (a *API) ActivePane(w *gotmux.Window) (*gotmux.Pane, error) {
+	panes, err := a.WindowPanes(w)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range panes {
+		if p.Active {
+			return p, nil
+		}
+	}
+
+	if len(panes) == 0 {
+		return nil, errors.New("window " + w.Name + " has no panes")
+	}
+	return panes[0], nil
+}
+
+// Creates a window at the end of the session, in the directory of the session, and runs the command in it.
+// The window is not selected.
+func (a *API) NewWindow(s *Session, name string, command string) (*gotmux.Window, error) {
+	window, err := s.NewWindow(&gotmux.NewWindowOptions{
+		StartDirectory: s.Path,
+		WindowName:     name,
+		DoNotAttach:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pane, err := a.ActivePane(window)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.sendCommand(pane.Id, command); err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+// Renames the window, which stops tmux from renaming it automatically.
+func (a *API) RenameWindow(w *gotmux.Window, name string) error {
+	if name == "" {
+		return errors.New("window name cannot be empty")
+	}
+
+	return w.Rename(name)
+}
+
+// Kills the window of the session. The session is killed with KillSession if it is its last window.
+func (a *API) KillWindow(s *Session, w *gotmux.Window) error {
+	if windows, err := a.SessionWindows(s); err == nil && len(windows) <= 1 {
+		return a.KillSession(s)
+	}
+
+	return w.Kill()
+}
+
+// Kills the pane of the window of the session. The window is killed with KillWindow if it is its last pane.
+func (a *API) KillPane(s *Session, w *gotmux.Window, p *gotmux.Pane) error {
+	if panes, err := a.WindowPanes(w); err == nil && len(panes) <= 1 {
+		return a.KillWindow(s, w)
+	}
+
+	return p.Kill()
+}
+
+// Makes the window the current window of its session, so that it is shown when attaching.
+func (a *API) SelectWindow(w *gotmux.Window) error {
+	return w.Select()
+}
+
+// Makes the pane and its window current in their session, so that the pane is focused when attaching.
+func (a *API) SelectPane(p *gotmux.Pane) error {
+	// a pane is a valid target for a window
+	if _, err := a.tmux.Command("select-window", "-t", p.Id); err != nil {
+		return errors.New("failed to select window of pane " + p.Id)
+	}
+
+	return p.Select()
+}
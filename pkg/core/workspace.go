@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sort"
@@ -27,6 +28,25 @@ func (w *Workspace) Path() string {
 	return filepath.Join(w.Topic.Path(), w.Name)
 }
 
+// Returns if the workspace is a link to a directory outside of the root.
+func (w *Workspace) Linked() bool {
+	fi, err := os.Lstat(w.Path())
+	return err == nil && fi.Mode()&os.ModeSymlink != 0
+}
+
+// Returns the directory the workspace is in, the target of the link for a linked workspace.
+func (w *Workspace) RealPath() string {
+	if !w.Linked() {
+		return w.Path()
+	}
+
+	target, err := filepath.EvalSymlinks(w.Path())
+	if err != nil {
+		return w.Path()
+	}
+	return target
+}
+
 func (w *Workspace) TmuxName() string {
 	return strings.ReplaceAll(w.Path(), ".", "_")
 }
@@ -62,8 +82,19 @@ func (w *Workspace) GitRemote() (string, error) {
 	return *(w.gitRemote), nil
 }
 
-func (w *Workspace) CloneRepo(url string) error {
-	err := GitClone(url, w.Path())
+// Returns the session layout of this workspace.
+// A layout in the workspace takes precedence over the layout of its topic, nil if neither exist.
+func (w *Workspace) Layout() (*Layout, error) {
+	layout, err := loadLayout(filepath.Join(w.Path(), ".mynav", LayoutFile))
+	if err != nil || layout != nil {
+		return layout, err
+	}
+
+	return w.Topic.Layout()
+}
+
+func (w *Workspace) CloneRepo(ctx context.Context, url string, opts *CloneOptions, onProgress func(string)) error {
+	err := GitClone(ctx, url, w.Path(), opts, onProgress)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,438 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// The escape sequence parser of gocui only understands a subset of SGR (one kind of color per sequence,
+// no bright colors, no attributes being turned off, no ':' separators) and prints anything else verbatim.
+// Terminal content (e.g. captured with capture-pane -e) is therefore interpreted here
+// and written back as simple sequences that gocui renders correctly.
+
+// text attributes, as bits of sgrState.attrs
+const (
+	sgrBold = 1 << iota
+	sgrFaint
+	sgrItalic
+	sgrUnderline
+	sgrBlink
+	sgrReverse
+	sgrStrike
+)
+
+// sgr parameter of each attribute, in the order they are written
+var sgrAttrs = []struct {
+	bit   int
+	param string
+}{
+	{sgrBold, "1"},
+	{sgrFaint, "2"},
+	{sgrItalic, "3"},
+	{sgrUnderline, "4"},
+	{sgrBlink, "5"},
+	{sgrReverse, "7"},
+	{sgrStrike, "9"},
+}
+
+type sgrColorKind int
+
+const (
+	sgrDefault sgrColorKind = iota
+	sgrIndexed
+	sgrRGB
+)
+
+// sgrColor is a color of the 256 color palette or a truecolor.
+type sgrColor struct {
+	kind    sgrColorKind
+	index   int
+	r, g, b int
+}
+
+// sgrState is the graphic rendition in effect at a point of the content.
+type sgrState struct {
+	fg, bg sgrColor
+	attrs  int
+}
+
+// Returns the sequences setting the state from a reset.
+func (s sgrState) sequence() string {
+	out := "\x1b[0m"
+	if seq := s.fg.sequence("38"); seq != "" {
+		out += seq
+	}
+
+	// gocui applies the attributes to the foreground, so they must come after the color
+	for _, attr := range sgrAttrs {
+		if s.attrs&attr.bit != 0 {
+			out += "\x1b[" + attr.param + "m"
+		}
+	}
+
+	if seq := s.bg.sequence("48"); seq != "" {
+		out += seq
+	}
+
+	return out
+}
+
+func (c sgrColor) sequence(target string) string {
+	switch c.kind {
+	case sgrIndexed:
+		return "\x1b[" + target + ";5;" + strconv.Itoa(c.index) + "m"
+	case sgrRGB:
+		return "\x1b[" + target + ";2;" + strconv.Itoa(c.r) + ";" + strconv.Itoa(c.g) + ";" + strconv.Itoa(c.b) + "m"
+	}
+
+	return ""
+}
+
+// Applies the parameters of an SGR sequence (the part between "\x1b[" and "m").
+func (s *sgrState) apply(params string) {
+	if params == "" {
+		*s = sgrState{}
+		return
+	}
+
+	fields := strings.Split(params, ";")
+	for i := 0; i < len(fields); i++ {
+		// sub parameters separated by ':' (e.g. 38:2::r:g:b, 4:3)
+		sub := strings.Split(fields[i], ":")
+		code := atoi(sub[0])
+		switch {
+		case code == 38 || code == 48 || code == 58:
+			var c sgrColor
+			if len(sub) > 1 {
+				c = parseColor(sub[1:], true)
+			} else {
+				var n int
+				c, n = parseExtendedColor(fields[i+1:])
+				i += n
+			}
+
+			// underline color (58) is not supported
+			if code == 38 {
+				s.fg = c
+			} else if code == 48 {
+				s.bg = c
+			}
+		case code == 0:
+			*s = sgrState{}
+		case code == 1:
+			s.attrs |= sgrBold
+		case code == 2:
+			s.attrs |= sgrFaint
+		case code == 3:
+			s.attrs |= sgrItalic
+		case code == 4:
+			// 4:0 turns the underline off, other styles are shown as a plain underline
+			if len(sub) > 1 && sub[1] == "0" {
+				s.attrs &^= sgrUnderline
+			} else {
+				s.attrs |= sgrUnderline
+			}
+		case code == 5 || code == 6:
+			s.attrs |= sgrBlink
+		case code == 7:
+			s.attrs |= sgrReverse
+		case code == 9:
+			s.attrs |= sgrStrike
+		case code == 21:
+			s.attrs |= sgrUnderline
+		case code == 22:
+			s.attrs &^= sgrBold | sgrFaint
+		case code == 23:
+			s.attrs &^= sgrItalic
+		case code == 24:
+			s.attrs &^= sgrUnderline
+		case code == 25:
+			s.attrs &^= sgrBlink
+		case code == 27:
+			s.attrs &^= sgrReverse
+		case code == 29:
+			s.attrs &^= sgrStrike
+		case code >= 30 && code <= 37:
+			s.fg = sgrColor{kind: sgrIndexed, index: code - 30}
+		case code == 39:
+			s.fg = sgrColor{}
+		case code >= 40 && code <= 47:
+			s.bg = sgrColor{kind: sgrIndexed, index: code - 40}
+		case code == 49:
+			s.bg = sgrColor{}
+		case code >= 90 && code <= 97:
+			s.fg = sgrColor{kind: sgrIndexed, index: code - 90 + 8}
+		case code >= 100 && code <= 107:
+			s.bg = sgrColor{kind: sgrIndexed, index: code - 100 + 8}
+		}
+	}
+}
+
+// Parses the color following 38 or 48 in ';' separated form (5;n or 2;r;g;b).
+// Returns the color and the number of fields consumed.
+func parseExtendedColor(fields []string) (sgrColor, int) {
+	if len(fields) == 0 {
+		return sgrColor{}, 0
+	}
+
+	switch atoi(fields[0]) {
+	case 5:
+		if len(fields) < 2 {
+			return sgrColor{}, len(fields)
+		}
+		return parseColor(fields[:2], false), 2
+	case 2:
+		if len(fields) < 4 {
+			return sgrColor{}, len(fields)
+		}
+		return parseColor(fields[:4], false), 4
+	}
+
+	return sgrColor{}, 1
+}
+
+// Parses a color given as 5,n or 2,r,g,b.
+// In ':' separated form, truecolors may include a color space id (2:id:r:g:b).
+func parseColor(parts []string, colorSpace bool) sgrColor {
+	switch atoi(parts[0]) {
+	case 5:
+		if len(parts) < 2 {
+			return sgrColor{}
+		}
+		return sgrColor{kind: sgrIndexed, index: min(atoi(parts[1]), 255)}
+	case 2:
+		if colorSpace && len(parts) >= 5 {
+			parts = parts[1:]
+		}
+		if len(parts) < 4 {
+			return sgrColor{}
+		}
+		return sgrColor{
+			kind: sgrRGB,
+			r:    min(atoi(parts[1]), 255),
+			g:    min(atoi(parts[2]), 255),
+			b:    min(atoi(parts[3]), 255),
+		}
+	}
+
+	return sgrColor{}
+}
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// TerminalCell is a character of terminal content with its graphic rendition.
+type TerminalCell struct {
+	Rune rune
+
+	// number of columns taken by the rune
+	Width int
+
+	state sgrState
+}
+
+// TerminalLine is a line of terminal content, one cell per character.
+type TerminalLine []TerminalCell
+
+// Highlight changes the rendition of a range of cells when rendering a line.
+type Highlight struct {
+	// range of cells, end excluded
+	Start int
+	End   int
+
+	// colors of the 256 color palette replacing the colors of the cells, 0 to keep them
+	Fg int
+	Bg int
+
+	// toggles reverse video
+	Reverse bool
+}
+
+// ParseTerminal splits the content of a terminal (text with escape sequences) into lines of cells.
+// Every SGR sequence is interpreted, other escape sequences and control characters are dropped.
+func ParseTerminal(content string) []TerminalLine {
+	lines := make([]TerminalLine, 0)
+	var state sgrState
+	for _, line := range strings.Split(content, "\n") {
+		cells := make(TerminalLine, 0, len(line))
+		col := 0
+		runes := []rune(line)
+		for i := 0; i < len(runes); i++ {
+			r := runes[i]
+			if r == 0x1b {
+				i = skipEscape(runes, i, &state)
+				continue
+			}
+
+			// gocui expands tabs to a fixed width, they are expanded here to the next tab stop instead
+			if r == '\t' {
+				for n := 8 - col%8; n > 0; n-- {
+					cells = append(cells, TerminalCell{Rune: ' ', Width: 1, state: state})
+				}
+				col += 8 - col%8
+				continue
+			}
+
+			// gocui does not support combining characters and other zero width runes
+			w := runewidth.RuneWidth(r)
+			if r < 0x20 || r == 0x7f || w == 0 {
+				continue
+			}
+
+			cells = append(cells, TerminalCell{Rune: r, Width: w, state: state})
+			col += w
+		}
+
+		lines = append(lines, cells)
+	}
+
+	return lines
+}
+
+// Returns the text of the line without its rendition.
+func (l TerminalLine) String() string {
+	runes := make([]rune, len(l))
+	for i, c := range l {
+		runes[i] = c.Rune
+	}
+	return string(runes)
+}
+
+// Returns the index of the cell at the column, the length of the line if the column is past its end.
+func (l TerminalLine) CellAt(col int) int {
+	x := 0
+	for i, c := range l {
+		if col < x+c.Width {
+			return i
+		}
+		x += c.Width
+	}
+	return len(l)
+}
+
+// Returns the line padded with blank cells to hold at least size cells.
+func (l TerminalLine) Pad(size int) TerminalLine {
+	for len(l) < size {
+		l = append(l, TerminalCell{Rune: ' ', Width: 1})
+	}
+	return l
+}
+
+// Returns the line as text that gocui renders faithfully, with the highlights applied in order.
+// The rendition is reset at the end of the line.
+func (l TerminalLine) Render(highlights ...Highlight) string {
+	out := &strings.Builder{}
+
+	// gocui starts with no attributes and default colors
+	var written sgrState
+	for i, c := range l {
+		cell := c.state
+		for _, h := range highlights {
+			if i < h.Start || i >= h.End {
+				continue
+			}
+
+			if h.Fg != 0 {
+				cell.fg = sgrColor{kind: sgrIndexed, index: h.Fg}
+			}
+			if h.Bg != 0 {
+				cell.bg = sgrColor{kind: sgrIndexed, index: h.Bg}
+			}
+			if h.Reverse {
+				cell.attrs ^= sgrReverse
+			}
+		}
+
+		if cell != written {
+			out.WriteString(cell.sequence())
+			written = cell
+		}
+		out.WriteRune(c.Rune)
+	}
+
+	if written != (sgrState{}) {
+		out.WriteString("\x1b[0m")
+	}
+
+	return out.String()
+}
+
+// RenderTerminal converts the content of a terminal (text with escape sequences) into text that gocui renders faithfully.
+// The cursor is drawn in reverse video at the cell (cursorX, cursorY), a negative position hides it.
+func RenderTerminal(content string, cursorX int, cursorY int) string {
+	lines := ParseTerminal(content)
+
+	// the cursor may be below the last line with content
+	for cursorY >= len(lines) && cursorX >= 0 {
+		lines = append(lines, TerminalLine{})
+	}
+
+	out := make([]string, len(lines))
+	for y, line := range lines {
+		if y != cursorY || cursorX < 0 {
+			out[y] = line.Render()
+			continue
+		}
+
+		// the cursor may be past the end of the line
+		i := line.CellAt(cursorX)
+		if i == len(line) {
+			x := 0
+			for _, c := range line {
+				x += c.Width
+			}
+			line = line.Pad(len(line) + cursorX - x + 1)
+			i = len(line) - 1
+		}
+
+		out[y] = line.Render(Highlight{Start: i, End: i + 1, Reverse: true})
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// Skips the escape sequence starting at i, applying it to the state if it is an SGR sequence.
+// Returns the index of the last rune of the sequence.
+func skipEscape(runes []rune, i int, state *sgrState) int {
+	if i+1 >= len(runes) {
+		return i
+	}
+
+	switch runes[i+1] {
+	case '[':
+		// CSI: parameters and intermediates up to a final byte in 0x40-0x7e
+		j := i + 2
+		for j < len(runes) && (runes[j] < 0x40 || runes[j] > 0x7e) {
+			j++
+		}
+		if j >= len(runes) {
+			return len(runes) - 1
+		}
+
+		if runes[j] == 'm' {
+			state.apply(string(runes[i+2 : j]))
+		}
+		return j
+	case ']', 'P', '_', '^':
+		// OSC (e.g. hyperlinks), DCS, APC and PM: terminated by BEL or ST (ESC \)
+		for j := i + 2; j < len(runes); j++ {
+			if runes[j] == 0x07 {
+				return j
+			}
+			if runes[j] == 0x1b && j+1 < len(runes) && runes[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(runes) - 1
+	}
+
+	// two character sequence
+	return i + 1
+}
@@ -36,6 +36,9 @@ type (
 		Cols     []string
 		Selected bool
 		Styles   []color.Style
+
+		// indexes of the runes to highlight in each col (e.g. characters matched by a search)
+		Matches [][]int
 	}
 )
 
@@ -187,21 +190,67 @@ func (tr *TableRenderer[T]) RenderTable(w io.Writer, onSelected func(int, *Table
 			colSize := proportion * float64(tr.table.Width)
 			colLine := Pad(col, int(math.Floor(colSize)))
 
-			var style color.Style
+			var style, matchStyle color.Style
 			if currentRow.Selected {
 				style = color.New(color.FgBlack, color.BgCyan)
+				matchStyle = color.New(color.FgRed, color.BgCyan, color.Bold)
 			} else if currentRow.Styles != nil && len(currentRow.Styles) >= i+1 {
 				style = currentRow.Styles[i]
+				matchStyle = color.New(color.FgYellow, color.Bold, color.OpUnderscore)
 			} else {
 				style = tr.table.Title.DefaultStyles[i]
+				matchStyle = color.New(color.FgYellow, color.Bold, color.OpUnderscore)
+			}
+
+			if len(currentRow.Matches) > i && len(currentRow.Matches[i]) > 0 {
+				line += highlightRunes(col, colLine, currentRow.Matches[i], style, matchStyle)
+			} else {
+				line += style.Sprint(colLine)
 			}
-			line += style.Sprint(colLine)
 		}
 
 		fmt.Fprintln(w, line)
 	})
 }
 
+// Styles the runes of the padded col at the indexes with matchStyle and the rest with style.
+// Runes cut off by the padding are not highlighted.
+func highlightRunes(col string, colLine string, indexes []int, style color.Style, matchStyle color.Style) string {
+	original := []rune(col)
+	runes := []rune(colLine)
+	highlighted := make([]bool, len(runes))
+	for _, idx := range indexes {
+		if idx < len(runes) && idx < len(original) && runes[idx] == original[idx] {
+			highlighted[idx] = true
+		}
+	}
+
+	// a truncated col ends with "..." which is never highlighted
+	if len(original) > len(runes) {
+		for idx := max(len(runes)-3, 0); idx < len(runes); idx++ {
+			highlighted[idx] = false
+		}
+	}
+
+	var out string
+	start := 0
+	for idx := 1; idx <= len(runes); idx++ {
+		if idx < len(runes) && highlighted[idx] == highlighted[start] {
+			continue
+		}
+
+		s := string(runes[start:idx])
+		if highlighted[start] {
+			out += matchStyle.Sprint(s)
+		} else {
+			out += style.Sprint(s)
+		}
+		start = idx
+	}
+
+	return out
+}
+
 func (tr *TableRenderer[T]) renderTitle(w io.Writer) {
 	var line string
 	for i, title := range tr.table.Title.Titles {
@@ -205,4 +205,10 @@ var translate = map[string]gocui.Key{
 	"CtrlH":      gocui.KeyCtrlH,
 	"Esc":        gocui.KeyEsc,
 	"Tab":        gocui.KeyTab,
+	"CtrlZ":      gocui.KeyCtrlZ,
+	"CtrlO":      gocui.KeyCtrlO,
+	"CtrlD":      gocui.KeyCtrlD,
+	"CtrlU":      gocui.KeyCtrlU,
+	"CtrlF":      gocui.KeyCtrlF,
+	"CtrlB":      gocui.KeyCtrlB,
 }